@@ -24,6 +24,7 @@ func (c *Client) FindAlertGroupSettings() ([]*AlertGroupSetting, error) {
 
 // FindAlertGroupSettingsContext finds alert group settings.
 func (c *Client) FindAlertGroupSettingsContext(ctx context.Context) ([]*AlertGroupSetting, error) {
+	ctx = withOperationName(ctx, "FindAlertGroupSettings")
 	data, err := requestGetContext[struct {
 		AlertGroupSettings []*AlertGroupSetting `json:"alertGroupSettings"`
 	}](ctx, c, "/api/v0/alert-group-settings")
@@ -40,6 +41,7 @@ func (c *Client) CreateAlertGroupSetting(param *AlertGroupSetting) (*AlertGroupS
 
 // CreateAlertGroupSettingContext creates an alert group setting.
 func (c *Client) CreateAlertGroupSettingContext(ctx context.Context, param *AlertGroupSetting) (*AlertGroupSetting, error) {
+	ctx = withOperationName(ctx, "CreateAlertGroupSetting")
 	return requestPostContext[AlertGroupSetting](ctx, c, "/api/v0/alert-group-settings", param)
 }
 
@@ -50,6 +52,7 @@ func (c *Client) GetAlertGroupSetting(id string) (*AlertGroupSetting, error) {
 
 // GetAlertGroupSettingContext gets an alert group setting.
 func (c *Client) GetAlertGroupSettingContext(ctx context.Context, id string) (*AlertGroupSetting, error) {
+	ctx = withOperationName(ctx, "GetAlertGroupSetting")
 	path := fmt.Sprintf("/api/v0/alert-group-settings/%s", id)
 	return requestGetContext[AlertGroupSetting](ctx, c, path)
 }
@@ -61,6 +64,7 @@ func (c *Client) UpdateAlertGroupSetting(id string, param *AlertGroupSetting) (*
 
 // UpdateAlertGroupSettingContext updates an alert group setting.
 func (c *Client) UpdateAlertGroupSettingContext(ctx context.Context, id string, param *AlertGroupSetting) (*AlertGroupSetting, error) {
+	ctx = withOperationName(ctx, "UpdateAlertGroupSetting")
 	path := fmt.Sprintf("/api/v0/alert-group-settings/%s", id)
 	return requestPutContext[AlertGroupSetting](ctx, c, path, param)
 }
@@ -72,6 +76,7 @@ func (c *Client) DeleteAlertGroupSetting(id string) (*AlertGroupSetting, error)
 
 // DeleteAlertGroupSettingContext deletes an alert group setting.
 func (c *Client) DeleteAlertGroupSettingContext(ctx context.Context, id string) (*AlertGroupSetting, error) {
+	ctx = withOperationName(ctx, "DeleteAlertGroupSetting")
 	path := fmt.Sprintf("/api/v0/alert-group-settings/%s", id)
 	return requestDeleteContext[AlertGroupSetting](ctx, c, path)
 }
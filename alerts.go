@@ -3,7 +3,9 @@ package mackerel
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
+	"strconv"
 )
 
 /*
@@ -101,6 +103,7 @@ func (c *Client) FindAlerts() (*AlertsResp, error) {
 
 // FindAlertsContext finds open alerts.
 func (c *Client) FindAlertsContext(ctx context.Context) (*AlertsResp, error) {
+	ctx = withOperationName(ctx, "FindAlerts")
 	return c.findAlertsWithParams(ctx, nil)
 }
 
@@ -111,6 +114,7 @@ func (c *Client) FindAlertsByNextID(nextID string) (*AlertsResp, error) {
 
 // FindAlertsByNextIDContext finds next open alerts by next id.
 func (c *Client) FindAlertsByNextIDContext(ctx context.Context, nextID string) (*AlertsResp, error) {
+	ctx = withOperationName(ctx, "FindAlertsByNextID")
 	params := url.Values{}
 	params.Set("nextId", nextID)
 	return c.findAlertsWithParams(ctx, params)
@@ -123,6 +127,7 @@ func (c *Client) FindWithClosedAlerts() (*AlertsResp, error) {
 
 // FindWithClosedAlertsContext finds open and close alerts.
 func (c *Client) FindWithClosedAlertsContext(ctx context.Context) (*AlertsResp, error) {
+	ctx = withOperationName(ctx, "FindWithClosedAlerts")
 	params := url.Values{}
 	params.Set("withClosed", "true")
 	return c.findAlertsWithParams(ctx, params)
@@ -135,12 +140,45 @@ func (c *Client) FindWithClosedAlertsByNextID(nextID string) (*AlertsResp, error
 
 // FindWithClosedAlertsByNextIDContext finds open and close alerts by next id.
 func (c *Client) FindWithClosedAlertsByNextIDContext(ctx context.Context, nextID string) (*AlertsResp, error) {
+	ctx = withOperationName(ctx, "FindWithClosedAlertsByNextID")
 	params := url.Values{}
 	params.Set("nextId", nextID)
 	params.Set("withClosed", "true")
 	return c.findAlertsWithParams(ctx, params)
 }
 
+// AlertsSeq returns an iterator over open alerts, or open and closed alerts if withClosed is true.
+// It fetches subsequent pages by nextId as the iteration proceeds.
+// Errors returned from the API are reported to the consumer via the second parameter of the iterator.
+func (c *Client) AlertsSeq(ctx context.Context, withClosed bool, opts *PageOptions) iter.Seq2[*Alert, error] {
+	return paginateByNextID(ctx, c, opts, func(ctx context.Context, nextID string) ([]*Alert, string, error) {
+		// The pages are named after the methods fetching them as in the other iterators.
+		name := "FindAlerts"
+		if withClosed {
+			name = "FindWithClosedAlerts"
+		}
+		if nextID != "" {
+			name += "ByNextID"
+		}
+		ctx = withOperationName(ctx, name)
+		params := url.Values{}
+		if nextID != "" {
+			params.Set("nextId", nextID)
+		}
+		if withClosed {
+			params.Set("withClosed", "true")
+		}
+		if n := opts.pageSize(); n > 0 {
+			params.Set("limit", strconv.Itoa(n))
+		}
+		res, err := c.findAlertsWithParams(ctx, params)
+		if err != nil {
+			return nil, "", err
+		}
+		return res.Alerts, res.NextID, nil
+	})
+}
+
 // GetAlert gets an alert.
 func (c *Client) GetAlert(alertID string) (*Alert, error) {
 	return c.GetAlertContext(context.Background(), alertID)
@@ -148,6 +186,7 @@ func (c *Client) GetAlert(alertID string) (*Alert, error) {
 
 // GetAlertContext gets an alert.
 func (c *Client) GetAlertContext(ctx context.Context, alertID string) (*Alert, error) {
+	ctx = withOperationName(ctx, "GetAlert")
 	path := fmt.Sprintf("/api/v0/alerts/%s", alertID)
 	return requestGetContext[Alert](ctx, c, path)
 }
@@ -159,6 +198,7 @@ func (c *Client) CloseAlert(alertID string, reason string) (*Alert, error) {
 
 // CloseAlertContext closes an alert.
 func (c *Client) CloseAlertContext(ctx context.Context, alertID string, reason string) (*Alert, error) {
+	ctx = withOperationName(ctx, "CloseAlert")
 	path := fmt.Sprintf("/api/v0/alerts/%s/close", alertID)
 	return requestPostContext[Alert](ctx, c, path, map[string]string{"reason": reason})
 }
@@ -170,6 +210,7 @@ func (c *Client) UpdateAlert(alertID string, param UpdateAlertParam) (*UpdateAle
 
 // UpdateAlertContext updates an alert.
 func (c *Client) UpdateAlertContext(ctx context.Context, alertID string, param UpdateAlertParam) (*UpdateAlertResponse, error) {
+	ctx = withOperationName(ctx, "UpdateAlert")
 	path := fmt.Sprintf("/api/v0/alerts/%s", alertID)
 	return requestPutContext[UpdateAlertResponse](ctx, c, path, param)
 }
@@ -192,9 +233,30 @@ func (c *Client) FindAlertLogs(alertId string, params *FindAlertLogsParam) (*Fin
 
 // FindAlertLogsContext gets alert logs.
 func (c *Client) FindAlertLogsContext(ctx context.Context, alertId string, params *FindAlertLogsParam) (*FindAlertLogsResp, error) {
+	ctx = withOperationName(ctx, "FindAlertLogs")
 	path := fmt.Sprintf("/api/v0/alerts/%s/logs", alertId)
 	if params == nil {
 		return requestGetContext[FindAlertLogsResp](ctx, c, path)
 	}
 	return requestGetWithParamsContext[FindAlertLogsResp](ctx, c, path, params.toValues())
 }
+
+// AlertLogsSeq returns an iterator over logs of the alert.
+// It fetches subsequent pages by nextId as the iteration proceeds.
+// Errors returned from the API are reported to the consumer via the second parameter of the iterator.
+func (c *Client) AlertLogsSeq(ctx context.Context, alertID string, opts *PageOptions) iter.Seq2[*AlertLog, error] {
+	return paginateByNextID(ctx, c, opts, func(ctx context.Context, nextID string) ([]*AlertLog, string, error) {
+		var params FindAlertLogsParam
+		if nextID != "" {
+			params.NextId = &nextID
+		}
+		if n := opts.pageSize(); n > 0 {
+			params.Limit = &n
+		}
+		res, err := c.FindAlertLogsContext(ctx, alertID, &params)
+		if err != nil {
+			return nil, "", err
+		}
+		return res.AlertLogs, res.NextID, nil
+	})
+}
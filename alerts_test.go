@@ -545,3 +545,78 @@ func TestFindAlertLogsWithOption(t *testing.T) {
 		t.Error("err should be nil but: ", err)
 	}
 }
+
+func TestAlertsSeq(t *testing.T) {
+	pages := map[string]*AlertsResp{
+		"": {
+			Alerts: []*Alert{{ID: "2wpLU5fBXbG", Status: "CRITICAL"}},
+			NextID: "2fsf8jRxFG1",
+		},
+		"2fsf8jRxFG1": {
+			Alerts: []*Alert{{ID: "2ust8jNxFH3", Status: "OK"}},
+		},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/v0/alerts" {
+			t.Error("request URL should be /api/v0/alerts but: ", req.URL.Path)
+		}
+		query := req.URL.Query()
+		if query.Get("withClosed") != "true" {
+			t.Error("request query withClosed should be true but: ", query.Get("withClosed"))
+		}
+		if query.Get("limit") != "1" {
+			t.Error("request query limit should be 1 but: ", query.Get("limit"))
+		}
+		respJSON, _ := json.Marshal(pages[query.Get("nextId")])
+		res.Header()["Content-Type"] = []string{"application/json"}
+		fmt.Fprint(res, string(respJSON)) // nolint
+	}))
+	defer ts.Close()
+
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+	var got []*Alert
+	for alert, err := range client.AlertsSeq(t.Context(), true, &PageOptions{PageSize: 1}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, alert)
+	}
+	want := append(pages[""].Alerts, pages["2fsf8jRxFG1"].Alerts...)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AlertsSeq should yield %v but %v", want, got)
+	}
+}
+
+func TestAlertLogsSeq(t *testing.T) {
+	pages := map[string]*FindAlertLogsResp{
+		"": {
+			AlertLogs: []*AlertLog{{ID: "log1", Status: "CRITICAL"}},
+			NextID:    "log2",
+		},
+		"log2": {
+			AlertLogs: []*AlertLog{{ID: "log2", Status: "OK"}},
+		},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/v0/alerts/2wpLU5fBXbG/logs" {
+			t.Error("request URL should be /api/v0/alerts/2wpLU5fBXbG/logs but: ", req.URL.Path)
+		}
+		respJSON, _ := json.Marshal(pages[req.URL.Query().Get("nextId")])
+		res.Header()["Content-Type"] = []string{"application/json"}
+		fmt.Fprint(res, string(respJSON)) // nolint
+	}))
+	defer ts.Close()
+
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+	var got []*AlertLog
+	for log, err := range client.AlertLogsSeq(t.Context(), "2wpLU5fBXbG", nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, log)
+	}
+	want := append(pages[""].AlertLogs, pages["log2"].AlertLogs...)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AlertLogsSeq should yield %v but %v", want, got)
+	}
+}
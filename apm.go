@@ -2,6 +2,7 @@ package mackerel
 
 import (
 	"context"
+	"iter"
 	"net/url"
 	"strconv"
 )
@@ -46,6 +47,7 @@ func (c *Client) ListHTTPServerStats(param *ListHTTPServerStatsParam) (*HTTPServ
 
 // ListHTTPServerStatsContext is like [ListHTTPServerStats].
 func (c *Client) ListHTTPServerStatsContext(ctx context.Context, param *ListHTTPServerStatsParam) (*HTTPServerStatsPageConnection, error) {
+	ctx = withOperationName(ctx, "ListHTTPServerStats")
 	params := url.Values{}
 	params.Set("serviceName", param.ServiceName)
 	params.Set("from", strconv.FormatInt(param.From, 10))
@@ -82,6 +84,28 @@ func (c *Client) ListHTTPServerStatsContext(ctx context.Context, param *ListHTTP
 	return requestGetWithParamsContext[HTTPServerStatsPageConnection](ctx, c, "/api/v0/apm/http-server-stats", params)
 }
 
+// ListHTTPServerStatsSeq returns an iterator over HTTP server statistics selected by param.
+// It fetches subsequent pages using the same param except [ListHTTPServerStatsParam.Page].
+// Errors returned from the API are reported to the consumer via the second parameter of the iterator.
+func (c *Client) ListHTTPServerStatsSeq(ctx context.Context, param *ListHTTPServerStatsParam, opts *PageOptions) iter.Seq2[*HTTPServerStats, error] {
+	var p ListHTTPServerStatsParam
+	if param != nil {
+		p = *param
+	}
+	if n := opts.pageSize(); n > 0 {
+		p.PerPage = &n
+	}
+	return paginate(ctx, c, opts, firstPage(p.Page), func(ctx context.Context, page int) ([]*HTTPServerStats, int, bool, error) {
+		param := p
+		param.Page = &page
+		res, err := c.ListHTTPServerStatsContext(ctx, &param)
+		if err != nil {
+			return nil, page, false, err
+		}
+		return res.Results, page + 1, res.HasNextPage, nil
+	})
+}
+
 // DbQueryStats represents database query statistics
 type DbQueryStats struct {
 	Query           string  `json:"query"`
@@ -119,6 +143,7 @@ func (c *Client) ListDbQueryStats(param *ListDbQueryStatsParam) (*DbQueryStatsPa
 
 // ListDbQueryStatsContext is like [ListDbQueryStats].
 func (c *Client) ListDbQueryStatsContext(ctx context.Context, param *ListDbQueryStatsParam) (*DbQueryStatsPageConnection, error) {
+	ctx = withOperationName(ctx, "ListDbQueryStats")
 	params := url.Values{}
 	params.Set("serviceName", param.ServiceName)
 	params.Set("from", strconv.FormatInt(param.From, 10))
@@ -151,3 +176,25 @@ func (c *Client) ListDbQueryStatsContext(ctx context.Context, param *ListDbQuery
 
 	return requestGetWithParamsContext[DbQueryStatsPageConnection](ctx, c, "/api/v0/apm/db-query-stats", params)
 }
+
+// ListDbQueryStatsSeq returns an iterator over database query statistics selected by param.
+// It fetches subsequent pages using the same param except [ListDbQueryStatsParam.Page].
+// Errors returned from the API are reported to the consumer via the second parameter of the iterator.
+func (c *Client) ListDbQueryStatsSeq(ctx context.Context, param *ListDbQueryStatsParam, opts *PageOptions) iter.Seq2[*DbQueryStats, error] {
+	var p ListDbQueryStatsParam
+	if param != nil {
+		p = *param
+	}
+	if n := opts.pageSize(); n > 0 {
+		p.PerPage = &n
+	}
+	return paginate(ctx, c, opts, firstPage(p.Page), func(ctx context.Context, page int) ([]*DbQueryStats, int, bool, error) {
+		param := p
+		param.Page = &page
+		res, err := c.ListDbQueryStatsContext(ctx, &param)
+		if err != nil {
+			return nil, page, false, err
+		}
+		return res.Results, page + 1, res.HasNextPage, nil
+	})
+}
@@ -606,3 +606,104 @@ func TestListDbQueryStatsContextWithCancel(t *testing.T) {
 		t.Error("err should not be nil for canceled context")
 	}
 }
+
+func TestListHTTPServerStatsSeq(t *testing.T) {
+	pages := []*HTTPServerStatsPageConnection{
+		{
+			Results:     []*HTTPServerStats{{Method: "GET", Route: "/api/users", RequestCount: 100}},
+			HasNextPage: true,
+		},
+		{
+			Results:     []*HTTPServerStats{{Method: "POST", Route: "/api/users", RequestCount: 10}},
+			HasNextPage: false,
+		},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		if query.Get("perPage") != "1" {
+			t.Error("request query 'perPage' param should be 1 but: ", query.Get("perPage"))
+		}
+		var page int
+		fmt.Sscan(query.Get("page"), &page) // nolint
+		respJSON, _ := json.Marshal(pages[page-1])
+		res.Header()["Content-Type"] = []string{"application/json"}
+		fmt.Fprint(res, string(respJSON)) // nolint
+	}))
+	defer ts.Close()
+
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+	var got []*HTTPServerStats
+	stats := client.ListHTTPServerStatsSeq(t.Context(), &ListHTTPServerStatsParam{
+		ServiceName: "test-service",
+		From:        1000000000,
+		To:          2000000000,
+	}, &PageOptions{PageSize: 1})
+	for s, err := range stats {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, s)
+	}
+	want := append(pages[0].Results, pages[1].Results...)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListHTTPServerStatsSeq should yield %v but %v", want, got)
+	}
+}
+
+func TestListDbQueryStatsSeq(t *testing.T) {
+	pages := []*DbQueryStatsPageConnection{
+		{
+			Results:     []*DbQueryStats{{Query: "SELECT 1", ExecutionCount: 100}},
+			HasNextPage: true,
+		},
+		{
+			Results:     []*DbQueryStats{{Query: "SELECT 2", ExecutionCount: 10}},
+			HasNextPage: false,
+		},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		var page int
+		fmt.Sscan(req.URL.Query().Get("page"), &page) // nolint
+		respJSON, _ := json.Marshal(pages[page-1])
+		res.Header()["Content-Type"] = []string{"application/json"}
+		fmt.Fprint(res, string(respJSON)) // nolint
+	}))
+	defer ts.Close()
+
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+	var got []*DbQueryStats
+	stats := client.ListDbQueryStatsSeq(t.Context(), &ListDbQueryStatsParam{
+		ServiceName: "test-service",
+		From:        1000000000,
+		To:          2000000000,
+	}, nil)
+	for s, err := range stats {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, s)
+	}
+	want := append(pages[0].Results, pages[1].Results...)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListDbQueryStatsSeq should yield %v but %v", want, got)
+	}
+}
+
+func TestListSeq_nilParam(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header()["Content-Type"] = []string{"application/json"}
+		fmt.Fprint(res, `{"results":[],"hasNextPage":false}`) // nolint
+	}))
+	defer ts.Close()
+
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+	for _, err := range client.ListHTTPServerStatsSeq(t.Context(), nil, nil) {
+		t.Error(err)
+	}
+	for _, err := range client.ListDbQueryStatsSeq(t.Context(), nil, nil) {
+		t.Error(err)
+	}
+	for _, err := range client.ListTracesSeq(t.Context(), nil) {
+		t.Error(err)
+	}
+}
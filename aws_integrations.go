@@ -94,6 +94,7 @@ func (c *Client) FindAWSIntegrations() ([]*AWSIntegration, error) {
 
 // FindAWSIntegrationsContext finds AWS integration settings.
 func (c *Client) FindAWSIntegrationsContext(ctx context.Context) ([]*AWSIntegration, error) {
+	ctx = withOperationName(ctx, "FindAWSIntegrations")
 	data, err := requestGetContext[struct {
 		AWSIntegrations []*AWSIntegration `json:"aws_integrations"`
 	}](ctx, c, "/api/v0/aws-integrations")
@@ -110,6 +111,7 @@ func (c *Client) CreateAWSIntegration(param *CreateAWSIntegrationParam) (*AWSInt
 
 // CreateAWSIntegrationContext creates an AWS integration setting.
 func (c *Client) CreateAWSIntegrationContext(ctx context.Context, param *CreateAWSIntegrationParam) (*AWSIntegration, error) {
+	ctx = withOperationName(ctx, "CreateAWSIntegration")
 	return requestPostContext[AWSIntegration](ctx, c, "/api/v0/aws-integrations", param)
 }
 
@@ -120,6 +122,7 @@ func (c *Client) FindAWSIntegration(awsIntegrationID string) (*AWSIntegration, e
 
 // FindAWSIntegrationContext finds an AWS integration setting.
 func (c *Client) FindAWSIntegrationContext(ctx context.Context, awsIntegrationID string) (*AWSIntegration, error) {
+	ctx = withOperationName(ctx, "FindAWSIntegration")
 	path := fmt.Sprintf("/api/v0/aws-integrations/%s", awsIntegrationID)
 	return requestGetContext[AWSIntegration](ctx, c, path)
 }
@@ -131,6 +134,7 @@ func (c *Client) UpdateAWSIntegration(awsIntegrationID string, param *UpdateAWSI
 
 // UpdateAWSIntegrationContext updates an AWS integration setting.
 func (c *Client) UpdateAWSIntegrationContext(ctx context.Context, awsIntegrationID string, param *UpdateAWSIntegrationParam) (*AWSIntegration, error) {
+	ctx = withOperationName(ctx, "UpdateAWSIntegration")
 	path := fmt.Sprintf("/api/v0/aws-integrations/%s", awsIntegrationID)
 	return requestPutContext[AWSIntegration](ctx, c, path, param)
 }
@@ -142,6 +146,7 @@ func (c *Client) DeleteAWSIntegration(awsIntegrationID string) (*AWSIntegration,
 
 // DeleteAWSIntegrationContext deletes an AWS integration setting.
 func (c *Client) DeleteAWSIntegrationContext(ctx context.Context, awsIntegrationID string) (*AWSIntegration, error) {
+	ctx = withOperationName(ctx, "DeleteAWSIntegration")
 	path := fmt.Sprintf("/api/v0/aws-integrations/%s", awsIntegrationID)
 	return requestDeleteContext[AWSIntegration](ctx, c, path)
 }
@@ -153,6 +158,7 @@ func (c *Client) CreateAWSIntegrationExternalID() (string, error) {
 
 // CreateAWSIntegrationExternalIDContext creates an AWS integration External ID.
 func (c *Client) CreateAWSIntegrationExternalIDContext(ctx context.Context) (string, error) {
+	ctx = withOperationName(ctx, "CreateAWSIntegrationExternalID")
 	data, err := requestPostContext[struct {
 		ExternalID string `json:"externalId"`
 	}](ctx, c, "/api/v0/aws-integrations-external-id", nil)
@@ -169,5 +175,6 @@ func (c *Client) ListAWSIntegrationExcludableMetrics() (*ListAWSIntegrationExclu
 
 // ListAWSIntegrationExcludableMetricsContext lists excludable metrics for AWS integration.
 func (c *Client) ListAWSIntegrationExcludableMetricsContext(ctx context.Context) (*ListAWSIntegrationExcludableMetrics, error) {
+	ctx = withOperationName(ctx, "ListAWSIntegrationExcludableMetrics")
 	return requestGetContext[ListAWSIntegrationExcludableMetrics](ctx, c, "/api/v0/aws-integrations-excludable-metrics")
 }
@@ -50,6 +50,7 @@ func (c *Client) FindChannels() ([]*Channel, error) {
 
 // FindChannelsContext finds channels.
 func (c *Client) FindChannelsContext(ctx context.Context) ([]*Channel, error) {
+	ctx = withOperationName(ctx, "FindChannels")
 	data, err := requestGetContext[struct {
 		Channels []*Channel `json:"channels"`
 	}](ctx, c, "/api/v0/channels")
@@ -66,6 +67,7 @@ func (c *Client) CreateChannel(param *Channel) (*Channel, error) {
 
 // CreateChannelContext creates a channel.
 func (c *Client) CreateChannelContext(ctx context.Context, param *Channel) (*Channel, error) {
+	ctx = withOperationName(ctx, "CreateChannel")
 	return requestPostContext[Channel](ctx, c, "/api/v0/channels", param)
 }
 
@@ -76,6 +78,7 @@ func (c *Client) UpdateChannel(channelId string, param *Channel) (*Channel, erro
 
 // UpdateChannelContext is like [UpdateChannel]
 func (c *Client) UpdateChannelContext(ctx context.Context, channelID string, param *Channel) (*Channel, error) {
+	ctx = withOperationName(ctx, "UpdateChannel")
 	data, err := requestPutContext[Channel](ctx, c, fmt.Sprintf("/api/v0/channels/%s", channelID), param)
 	if err != nil {
 		return nil, err
@@ -90,6 +93,7 @@ func (c *Client) DeleteChannel(channelID string) (*Channel, error) {
 
 // DeleteChannelContext deletes a channel.
 func (c *Client) DeleteChannelContext(ctx context.Context, channelID string) (*Channel, error) {
+	ctx = withOperationName(ctx, "DeleteChannel")
 	path := fmt.Sprintf("/api/v0/channels/%s", channelID)
 	return requestDeleteContext[Channel](ctx, c, path)
 }
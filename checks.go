@@ -3,6 +3,7 @@ package mackerel
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
 )
 
@@ -103,12 +104,33 @@ func (c *Client) FindCheckMonitors(params *FindCheckMonitorsParam) (*FindCheckMo
 
 // FindCheckMonitorsContext finds check monitors.
 func (c *Client) FindCheckMonitorsContext(ctx context.Context, params *FindCheckMonitorsParam) (*FindCheckMonitorsResp, error) {
+	ctx = withOperationName(ctx, "FindCheckMonitors")
 	if params == nil {
 		return requestGetContext[FindCheckMonitorsResp](ctx, c, "/api/v0/monitoring/checks")
 	}
 	return requestGetWithParamsContext[FindCheckMonitorsResp](ctx, c, "/api/v0/monitoring/checks", params.toValues())
 }
 
+// CheckMonitorsSeq returns an iterator over check monitors.
+// It fetches subsequent pages by nextId as the iteration proceeds.
+// Errors returned from the API are reported to the consumer via the second parameter of the iterator.
+func (c *Client) CheckMonitorsSeq(ctx context.Context, opts *PageOptions) iter.Seq2[*CheckMonitor, error] {
+	return paginateByNextID(ctx, c, opts, func(ctx context.Context, nextID string) ([]*CheckMonitor, string, error) {
+		var params FindCheckMonitorsParam
+		if nextID != "" {
+			params.NextID = &nextID
+		}
+		if n := opts.pageSize(); n > 0 {
+			params.Limit = &n
+		}
+		res, err := c.FindCheckMonitorsContext(ctx, &params)
+		if err != nil {
+			return nil, "", err
+		}
+		return res.Checks, res.NextID, nil
+	})
+}
+
 // PostCheckReports reports check monitoring results.
 func (c *Client) PostCheckReports(checkReports *CheckReports) error {
 	return c.PostCheckReportsContext(context.Background(), checkReports)
@@ -116,6 +138,7 @@ func (c *Client) PostCheckReports(checkReports *CheckReports) error {
 
 // PostCheckReportsContext reports check monitoring results.
 func (c *Client) PostCheckReportsContext(ctx context.Context, checkReports *CheckReports) error {
+	ctx = withOperationName(ctx, "PostCheckReports")
 	_, err := requestPostContext[any](ctx, c, "/api/v0/monitoring/checks/report", checkReports)
 	return err
 }
@@ -171,3 +171,36 @@ func TestClient_PostCheckReports(t *testing.T) {
 	}
 
 }
+
+func TestCheckMonitorsSeq(t *testing.T) {
+	pages := map[string]any{
+		"": map[string]any{
+			"checks": []map[string]string{{"id": "checkId1", "name": "check1"}},
+			"nextId": "checkId2",
+		},
+		"checkId2": map[string]any{
+			"checks": []map[string]string{{"id": "checkId2", "name": "check2"}},
+		},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/v0/monitoring/checks" {
+			t.Error("request URL should be /api/v0/monitoring/checks but: ", req.URL.Path)
+		}
+		respJSON, _ := json.Marshal(pages[req.URL.Query().Get("nextId")])
+		res.Header()["Content-Type"] = []string{"application/json"}
+		fmt.Fprint(res, string(respJSON)) // nolint
+	}))
+	defer ts.Close()
+
+	cli, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+	var names []string
+	for check, err := range cli.CheckMonitorsSeq(t.Context(), nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, check.Name)
+	}
+	if len(names) != 2 || names[0] != "check1" || names[1] != "check2" {
+		t.Error("CheckMonitorsSeq should yield check1 and check2 but: ", names)
+	}
+}
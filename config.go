@@ -0,0 +1,203 @@
+package mackerel
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Environment variables read by [LoadConfig].
+const (
+	EnvAPIKey  = "MACKEREL_APIKEY"
+	EnvAPIBase = "MACKEREL_APIBASE"
+	EnvProfile = "MACKEREL_PROFILE"
+)
+
+// ErrNoAPIKey is returned by [LoadConfig] when no API key is configured.
+var ErrNoAPIKey = errors.New("API key is not configured")
+
+// Config is the configuration of [Client] resolved by [LoadConfig].
+type Config struct {
+	APIKey string
+	// APIBase is the base URL of the API. Empty means the default.
+	APIBase string
+	// HTTPProxy is the URL of the proxy. Empty means the proxy of the environment.
+	HTTPProxy string
+}
+
+// LoadConfigOptions configures [LoadConfig].
+type LoadConfigOptions struct {
+	// Profile is the name of the profile to use. It takes precedence over
+	// MACKEREL_APIKEY and MACKEREL_APIBASE environment variables, which are then ignored.
+	// When empty, MACKEREL_PROFILE environment variable is used.
+	Profile string
+	// ProfilesPath is the path of the profile file.
+	// The default is mackerel/profiles.toml in [os.UserConfigDir].
+	ProfilesPath string
+	// AgentConfigPath is the path of mackerel-agent.conf.
+	// The default is that of the mackerel-agent package of the platform.
+	AgentConfigPath string
+}
+
+// LoadConfig resolves the configuration in the same way as the Mackerel tools.
+// The sources below are read in order, and the latter ones take precedence.
+//
+//  1. apikey, apibase and http_proxy of mackerel-agent.conf, if it exists.
+//  2. The profile of the profile file, if a profile is specified by MACKEREL_PROFILE.
+//  3. MACKEREL_APIKEY and MACKEREL_APIBASE environment variables.
+//
+// A profile specified by the options is read last instead, and the environment variables of the API key
+// and the API base are ignored so that the requests are never sent to another organization.
+//
+// The profile file is a TOML file that has a table of the same keys as mackerel-agent.conf for each profile:
+//
+//	[production]
+//	apikey = "..."
+//
+//	[staging]
+//	apikey = "..."
+//	apibase = "https://api.example.com/"
+//
+// Only the top-level string values of mackerel-agent.conf are read, and the other values are ignored.
+// Errors reading the default mackerel-agent.conf are ignored when the API key is configured by the other sources.
+func LoadConfig(opts *LoadConfigOptions) (*Config, error) {
+	var o LoadConfigOptions
+	if opts != nil {
+		o = *opts
+	}
+	var cfg Config
+
+	agentConfigPath := o.AgentConfigPath
+	if agentConfigPath == "" {
+		agentConfigPath = defaultAgentConfigPath()
+	}
+	tables, agentConfigErr := readTOMLStrings(agentConfigPath)
+	if agentConfigErr != nil {
+		if o.AgentConfigPath != "" {
+			return nil, agentConfigErr
+		}
+		if errors.Is(agentConfigErr, fs.ErrNotExist) {
+			agentConfigErr = nil
+		}
+	}
+	cfg.merge(tables[""])
+
+	profile := o.Profile
+	if profile == "" {
+		profile = os.Getenv(EnvProfile)
+	}
+	if profile != "" {
+		profilesPath := o.ProfilesPath
+		if profilesPath == "" {
+			dir, err := os.UserConfigDir()
+			if err != nil {
+				return nil, err
+			}
+			profilesPath = filepath.Join(dir, "mackerel", "profiles.toml")
+		}
+		tables, err := readTOMLStrings(profilesPath)
+		if err != nil {
+			return nil, err
+		}
+		values, ok := tables[profile]
+		if !ok {
+			return nil, fmt.Errorf("profile %q is not found in %s", profile, profilesPath)
+		}
+		cfg.merge(values)
+	}
+
+	if o.Profile == "" {
+		cfg.merge(map[string]string{
+			"apikey":  os.Getenv(EnvAPIKey),
+			"apibase": os.Getenv(EnvAPIBase),
+		})
+	}
+	if cfg.APIKey == "" {
+		// The default mackerel-agent.conf may be unreadable for users other than root,
+		// which matters only when the API key is not configured elsewhere.
+		if agentConfigErr != nil {
+			return nil, agentConfigErr
+		}
+		return nil, ErrNoAPIKey
+	}
+	return &cfg, nil
+}
+
+func (cfg *Config) merge(values map[string]string) {
+	if v := values["apikey"]; v != "" {
+		cfg.APIKey = v
+	}
+	if v := values["apibase"]; v != "" {
+		cfg.APIBase = v
+	}
+	if v := values["http_proxy"]; v != "" {
+		cfg.HTTPProxy = v
+	}
+}
+
+// NewClient returns a new Client configured by cfg and opts.
+func (cfg *Config) NewClient(opts ...Option) (*Client, error) {
+	var cfgOpts []Option
+	if cfg.APIBase != "" {
+		cfgOpts = append(cfgOpts, WithBaseURL(cfg.APIBase))
+	}
+	if cfg.HTTPProxy != "" {
+		cfgOpts = append(cfgOpts, WithProxy(cfg.HTTPProxy))
+	}
+	return New(cfg.APIKey, append(cfgOpts, opts...)...)
+}
+
+// NewClientFromConfig returns a new Client configured by [LoadConfig] and clientOpts.
+func NewClientFromConfig(opts *LoadConfigOptions, clientOpts ...Option) (*Client, error) {
+	cfg, err := LoadConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.NewClient(clientOpts...)
+}
+
+// defaultAgentConfigPath is a variable to be replaced in tests.
+var defaultAgentConfigPath = func() string {
+	switch runtime.GOOS {
+	case "windows":
+		dir := os.Getenv("ProgramFiles")
+		if dir == "" {
+			dir = `C:\Program Files`
+		}
+		return filepath.Join(dir, "Mackerel", "mackerel-agent", "mackerel-agent.conf")
+	case "darwin":
+		return "/usr/local/etc/mackerel-agent.conf"
+	default:
+		return "/etc/mackerel-agent/mackerel-agent.conf"
+	}
+}
+
+// readTOMLStrings reads the string values of a TOML file by their tables and keys.
+// The values of the top-level are keyed by "". Values of the other types are skipped.
+func readTOMLStrings(path string) (map[string]map[string]string, error) {
+	var doc map[string]any
+	if _, err := toml.DecodeFile(path, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	tables := map[string]map[string]string{"": {}}
+	for key, v := range doc {
+		switch v := v.(type) {
+		case string:
+			tables[""][key] = v
+		case map[string]any:
+			values := make(map[string]string)
+			for k, v := range v {
+				if s, ok := v.(string); ok {
+					values[k] = s
+				}
+			}
+			tables[key] = values
+		}
+	}
+	return tables, nil
+}
@@ -0,0 +1,167 @@
+package mackerel
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+const testAgentConfig = `# mackerel-agent.conf
+apikey = "agent-api-key" # comment
+pidfile = '/var/run/mackerel-agent.pid'
+http_proxy = "http://proxy.example.com:8080"
+roles = [
+  "My-Service:app",
+  "My-Service:db",
+]
+display_name = """
+apikey = "not-a-key"
+"""
+filesystems = { ignore = "/dev/ram.*", use_mountpoint = true }
+
+[host_status]
+on_start = "working"
+
+[plugin.metrics.vmstat]
+command = ["mackerel-plugin-linux", "-type", "vmstat"]
+apikey = "not-a-key"
+`
+
+const testProfiles = `
+[production]
+apikey = "production-api-key"
+
+["staging"]
+apikey = "staging-api-key"
+apibase = "https://staging.example.com/"
+`
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	agentConfig := writeTestFile(t, "mackerel-agent.conf", testAgentConfig)
+	profiles := writeTestFile(t, "profiles.toml", testProfiles)
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		opts LoadConfigOptions
+		want *Config
+	}{
+		{
+			name: "agent config",
+			opts: LoadConfigOptions{AgentConfigPath: agentConfig},
+			want: &Config{APIKey: "agent-api-key", HTTPProxy: "http://proxy.example.com:8080"},
+		},
+		{
+			name: "profile",
+			opts: LoadConfigOptions{AgentConfigPath: agentConfig, ProfilesPath: profiles, Profile: "staging"},
+			want: &Config{APIKey: "staging-api-key", APIBase: "https://staging.example.com/", HTTPProxy: "http://proxy.example.com:8080"},
+		},
+		{
+			name: "profile by environment variable",
+			env:  map[string]string{EnvProfile: "production"},
+			opts: LoadConfigOptions{AgentConfigPath: agentConfig, ProfilesPath: profiles},
+			want: &Config{APIKey: "production-api-key", HTTPProxy: "http://proxy.example.com:8080"},
+		},
+		{
+			name: "environment variables",
+			env:  map[string]string{EnvAPIKey: "env-api-key", EnvAPIBase: "https://env.example.com/", EnvProfile: "staging"},
+			opts: LoadConfigOptions{AgentConfigPath: agentConfig, ProfilesPath: profiles},
+			want: &Config{APIKey: "env-api-key", APIBase: "https://env.example.com/", HTTPProxy: "http://proxy.example.com:8080"},
+		},
+		{
+			name: "profile over environment variables",
+			env:  map[string]string{EnvAPIKey: "env-api-key", EnvAPIBase: "https://env.example.com/", EnvProfile: "staging"},
+			opts: LoadConfigOptions{AgentConfigPath: agentConfig, ProfilesPath: profiles, Profile: "production"},
+			want: &Config{APIKey: "production-api-key", HTTPProxy: "http://proxy.example.com:8080"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvAPIKey, "")
+			t.Setenv(EnvAPIBase, "")
+			t.Setenv(EnvProfile, "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			cfg, err := LoadConfig(&tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := pretty.Compare(cfg, tt.want); diff != "" {
+				t.Errorf("config should be %+v but: %s", tt.want, diff)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	t.Setenv(EnvAPIKey, "")
+	t.Setenv(EnvAPIBase, "")
+	t.Setenv(EnvProfile, "")
+	empty := writeTestFile(t, "mackerel-agent.conf", "")
+	profiles := writeTestFile(t, "profiles.toml", testProfiles)
+
+	if _, err := LoadConfig(&LoadConfigOptions{AgentConfigPath: empty}); !errors.Is(err, ErrNoAPIKey) {
+		t.Error("LoadConfig without API key should return ErrNoAPIKey but: ", err)
+	}
+	if _, err := LoadConfig(&LoadConfigOptions{AgentConfigPath: empty, ProfilesPath: profiles, Profile: "unknown"}); err == nil {
+		t.Error("LoadConfig with an unknown profile should fail")
+	}
+	if _, err := LoadConfig(&LoadConfigOptions{AgentConfigPath: filepath.Join(t.TempDir(), "missing.conf")}); !errors.Is(err, os.ErrNotExist) {
+		t.Error("LoadConfig with a missing agent config should fail but: ", err)
+	}
+	invalid := writeTestFile(t, "invalid.conf", `apikey = "unterminated`)
+	if _, err := LoadConfig(&LoadConfigOptions{AgentConfigPath: invalid}); err == nil {
+		t.Error("LoadConfig with an invalid agent config should fail")
+	}
+}
+
+func TestLoadConfig_UnreadableDefaultAgentConfig(t *testing.T) {
+	t.Setenv(EnvAPIBase, "")
+	t.Setenv(EnvProfile, "")
+	// Reading a directory fails with an error other than fs.ErrNotExist as permission errors do.
+	dir := t.TempDir()
+	orig := defaultAgentConfigPath
+	defaultAgentConfigPath = func() string { return dir }
+	t.Cleanup(func() { defaultAgentConfigPath = orig })
+
+	t.Setenv(EnvAPIKey, "env-api-key")
+	cfg, err := LoadConfig(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APIKey != "env-api-key" {
+		t.Errorf("API key should be read from the environment variable but: %q", cfg.APIKey)
+	}
+
+	t.Setenv(EnvAPIKey, "")
+	if _, err := LoadConfig(nil); err == nil || errors.Is(err, ErrNoAPIKey) {
+		t.Error("the error of the default agent config should be returned without API key but: ", err)
+	}
+}
+
+func TestConfig_NewClient(t *testing.T) {
+	cfg := &Config{APIKey: "dummy-key", APIBase: "https://api.example.com/", HTTPProxy: "http://proxy.example.com:8080"}
+	client, err := cfg.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.APIKey != "dummy-key" || client.BaseURL.String() != "https://api.example.com/" {
+		t.Error("client should be configured by cfg but: ", client.APIKey, client.BaseURL)
+	}
+	if client.HTTPClient.Transport == nil {
+		t.Error("client should have a transport with the proxy")
+	}
+}
@@ -253,6 +253,7 @@ func (c *Client) FindDashboards() ([]*Dashboard, error) {
 
 // FindDashboardsContext finds dashboards.
 func (c *Client) FindDashboardsContext(ctx context.Context) ([]*Dashboard, error) {
+	ctx = withOperationName(ctx, "FindDashboards")
 	data, err := requestGetContext[struct {
 		Dashboards []*Dashboard `json:"dashboards"`
 	}](ctx, c, "/api/v0/dashboards")
@@ -269,6 +270,7 @@ func (c *Client) CreateDashboard(param *Dashboard) (*Dashboard, error) {
 
 // CreateDashboardContext creates a dashboard.
 func (c *Client) CreateDashboardContext(ctx context.Context, param *Dashboard) (*Dashboard, error) {
+	ctx = withOperationName(ctx, "CreateDashboard")
 	return requestPostContext[Dashboard](ctx, c, "/api/v0/dashboards", param)
 }
 
@@ -279,6 +281,7 @@ func (c *Client) FindDashboard(dashboardID string) (*Dashboard, error) {
 
 // FindDashboardContext finds a dashboard.
 func (c *Client) FindDashboardContext(ctx context.Context, dashboardID string) (*Dashboard, error) {
+	ctx = withOperationName(ctx, "FindDashboard")
 	path := fmt.Sprintf("/api/v0/dashboards/%s", dashboardID)
 	return requestGetContext[Dashboard](ctx, c, path)
 }
@@ -290,6 +293,7 @@ func (c *Client) UpdateDashboard(dashboardID string, param *Dashboard) (*Dashboa
 
 // UpdateDashboardContext updates a dashboard.
 func (c *Client) UpdateDashboardContext(ctx context.Context, dashboardID string, param *Dashboard) (*Dashboard, error) {
+	ctx = withOperationName(ctx, "UpdateDashboard")
 	path := fmt.Sprintf("/api/v0/dashboards/%s", dashboardID)
 	return requestPutContext[Dashboard](ctx, c, path, param)
 }
@@ -301,6 +305,7 @@ func (c *Client) DeleteDashboard(dashboardID string) (*Dashboard, error) {
 
 // DeleteDashboardContext deletes a dashboard.
 func (c *Client) DeleteDashboardContext(ctx context.Context, dashboardID string) (*Dashboard, error) {
+	ctx = withOperationName(ctx, "DeleteDashboard")
 	path := fmt.Sprintf("/api/v0/dashboards/%s", dashboardID)
 	return requestDeleteContext[Dashboard](ctx, c, path)
 }
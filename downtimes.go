@@ -135,6 +135,7 @@ func (c *Client) FindDowntimes() ([]*Downtime, error) {
 
 // FindDowntimesContext finds downtimes.
 func (c *Client) FindDowntimesContext(ctx context.Context) ([]*Downtime, error) {
+	ctx = withOperationName(ctx, "FindDowntimes")
 	data, err := requestGetContext[struct {
 		Downtimes []*Downtime `json:"downtimes"`
 	}](ctx, c, "/api/v0/downtimes")
@@ -151,6 +152,7 @@ func (c *Client) CreateDowntime(param *Downtime) (*Downtime, error) {
 
 // CreateDowntimeContext creates a downtime.
 func (c *Client) CreateDowntimeContext(ctx context.Context, param *Downtime) (*Downtime, error) {
+	ctx = withOperationName(ctx, "CreateDowntime")
 	return requestPostContext[Downtime](ctx, c, "/api/v0/downtimes", param)
 }
 
@@ -161,6 +163,7 @@ func (c *Client) UpdateDowntime(downtimeID string, param *Downtime) (*Downtime,
 
 // UpdateDowntimeContext updates a downtime.
 func (c *Client) UpdateDowntimeContext(ctx context.Context, downtimeID string, param *Downtime) (*Downtime, error) {
+	ctx = withOperationName(ctx, "UpdateDowntime")
 	path := fmt.Sprintf("/api/v0/downtimes/%s", downtimeID)
 	return requestPutContext[Downtime](ctx, c, path, param)
 }
@@ -172,6 +175,7 @@ func (c *Client) DeleteDowntime(downtimeID string) (*Downtime, error) {
 
 // DeleteDowntimeContext deletes a downtime.
 func (c *Client) DeleteDowntimeContext(ctx context.Context, downtimeID string) (*Downtime, error) {
+	ctx = withOperationName(ctx, "DeleteDowntime")
 	path := fmt.Sprintf("/api/v0/downtimes/%s", downtimeID)
 	return requestDeleteContext[Downtime](ctx, c, path)
 }
@@ -0,0 +1,127 @@
+package mackerel
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+)
+
+// ErrDryRun is returned by mutating methods of Client in dry-run mode.
+var ErrDryRun = errors.New("request is not sent in dry-run mode")
+
+// readOnlyPostRoutes are the routes of POST requests that do not change anything.
+var readOnlyPostRoutes = []string{
+	"/api/v0/traces",
+}
+
+// PlannedRequest is a request recorded by [DryRun] instead of being sent.
+type PlannedRequest struct {
+	// Operation is the name of the Client method, such as "CreateMonitor".
+	Operation string
+	Method    string
+	Path      string
+	Query     url.Values
+	// Body is the JSON body of the request, or nil if the request has no body.
+	Body json.RawMessage
+	// Payload is the value encoded to Body.
+	Payload any
+}
+
+// DryRun makes Client record mutating requests into a plan instead of sending them.
+// Requests that only read, such as GET requests, are sent as usual.
+// It is safe for concurrent use.
+type DryRun struct {
+	// Synthesize makes mutating methods return a result decoded from the request body,
+	// or from an empty object if the request has no body, instead of ErrDryRun.
+	// The result lacks the values assigned by the API such as IDs,
+	// and methods whose result cannot be decoded from it still return an error.
+	Synthesize bool
+
+	mu   sync.Mutex
+	plan []*PlannedRequest
+}
+
+// WithDryRun sets DryRun of the client.
+func WithDryRun(d *DryRun) Option {
+	return func(o *clientOptions) error {
+		o.dryRun = d
+		return nil
+	}
+}
+
+// Plan returns the requests recorded so far, in the order they are made.
+func (d *DryRun) Plan() []*PlannedRequest {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.plan)
+}
+
+// Reset clears the plan.
+func (d *DryRun) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.plan = nil
+}
+
+func isMutating(op *Operation) bool {
+	switch op.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	case http.MethodPost:
+		return !slices.Contains(readOnlyPostRoutes, op.Route())
+	}
+	return true
+}
+
+// wrap returns a handler that records mutating requests instead of passing them to next.
+func (d *DryRun) wrap(next Handler) Handler {
+	return func(op *Operation, req *http.Request) (*http.Response, error) {
+		if !isMutating(op) {
+			return next(op, req)
+		}
+		body, ok := requestBody(req)
+		if !ok && req.Body != nil && req.Body != http.NoBody {
+			var err error
+			if body, err = io.ReadAll(req.Body); err != nil {
+				return nil, err
+			}
+		}
+		body = bytes.TrimSpace(body)
+		planned := &PlannedRequest{
+			Operation: op.Name,
+			Method:    op.Method,
+			Path:      op.Path,
+			Query:     op.Query,
+			Payload:   op.Payload,
+		}
+		if len(body) > 0 {
+			planned.Body = json.RawMessage(body)
+		}
+		d.mu.Lock()
+		d.plan = append(d.plan, planned)
+		d.mu.Unlock()
+
+		if !d.Synthesize {
+			return nil, ErrDryRun
+		}
+		if len(body) == 0 {
+			body = []byte("{}")
+		}
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"application/json"}},
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+}
@@ -0,0 +1,82 @@
+package mackerel
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	var sent []string
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		sent = append(sent, req.Method+" "+req.URL.Path)
+		switch req.URL.Path {
+		case "/api/v0/traces":
+			res.Write([]byte(`{"results":[],"hasNextPage":false}`)) // nolint
+		default:
+			res.Write([]byte(`{"hosts":[]}`)) // nolint
+		}
+	}))
+	defer ts.Close()
+
+	dryRun := &DryRun{}
+	client, err := New("dummy-key", WithBaseURL(ts.URL), WithDryRun(dryRun))
+	if err != nil {
+		t.Fatal(err)
+	}
+	param := &MonitorConnectivity{Type: "connectivity", Name: "connectivity"}
+	if _, err := client.CreateMonitorContext(t.Context(), param); !errors.Is(err, ErrDryRun) {
+		t.Error("CreateMonitor should return ErrDryRun but: ", err)
+	}
+	if err := client.DeleteHostMetaDataContext(t.Context(), "9rxGOHfVF8F", "inventory"); !errors.Is(err, ErrDryRun) {
+		t.Error("DeleteHostMetaData should return ErrDryRun but: ", err)
+	}
+	if _, err := client.FindHostsContext(t.Context(), &FindHostsParam{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ListTracesContext(t.Context(), &ListTracesParam{ServiceName: "My-Service"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sent) != 2 || sent[0] != "GET /api/v0/hosts" || sent[1] != "POST /api/v0/traces" {
+		t.Error("only read requests should be sent but: ", sent)
+	}
+	plan := dryRun.Plan()
+	if len(plan) != 2 {
+		t.Fatal("2 requests should be planned but: ", len(plan))
+	}
+	if p := plan[0]; p.Operation != "CreateMonitor" || p.Method != http.MethodPost || p.Path != "/api/v0/monitors" ||
+		string(p.Body) != `{"name":"connectivity","type":"connectivity"}` || p.Payload != param {
+		t.Errorf("CreateMonitor should be planned but: %+v %s", p, p.Body)
+	}
+	if p := plan[1]; p.Operation != "DeleteHostMetaData" || p.Method != http.MethodDelete || p.Body != nil {
+		t.Errorf("DeleteHostMetaData should be planned but: %+v", p)
+	}
+	dryRun.Reset()
+	if len(dryRun.Plan()) != 0 {
+		t.Error("plan should be cleared")
+	}
+}
+
+func TestDryRun_Synthesize(t *testing.T) {
+	client, _ := New("dummy-key", WithBaseURL("http://127.0.0.1:0"), WithDryRun(&DryRun{Synthesize: true}))
+	service, err := client.CreateServiceContext(t.Context(), &CreateServiceParam{Name: "My-Service", Memo: "memo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if service.Name != "My-Service" || service.Memo != "memo" {
+		t.Error("result should be synthesized from the request but: ", service)
+	}
+	if err := client.RetireHostContext(t.Context(), "9rxGOHfVF8F"); err != nil {
+		t.Error("RetireHost should succeed but: ", err)
+	}
+	resp, err := client.PutJSONContext(t.Context(), "/api/v0/custom", map[string]int{"a": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close() // nolint
+	if resp.StatusCode != http.StatusOK {
+		t.Error("status code should be 200 but: ", resp.StatusCode)
+	}
+}
@@ -2,29 +2,119 @@ package mackerel
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 )
 
+// Errors that [APIError] matches with errors.Is according to its status code.
+var (
+	// ErrValidation is matched by 400 Bad Request and 422 Unprocessable Entity.
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// requestIDHeader is the response header that identifies the request on the server side.
+const requestIDHeader = "X-Request-Id"
+
 // APIError represents the error type from Mackerel API.
 type APIError struct {
 	StatusCode int
 	Message    string
+
+	// Method and Path are those of the failed request.
+	Method string
+	Path   string
+	// RequestID is the value of X-Request-Id response header, if any.
+	RequestID string
+	// Body is the raw response body.
+	Body []byte
+	// Fields holds the per-field details of a validation error, if the API reports them.
+	Fields []FieldError
+}
+
+// FieldError describes why a field of the request payload is rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 func (err *APIError) Error() string {
 	return fmt.Sprintf("API request failed: %s", err.Message)
 }
 
-func extractErrorMessage(r io.Reader) (errorMessage string, err error) {
-	bs, err := io.ReadAll(r)
-	if err != nil {
-		return "", err
+// Is reports whether err corresponds to target, one of the sentinel errors such as [ErrNotFound].
+func (err *APIError) Is(target error) bool {
+	switch err.StatusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return target == ErrValidation
+	case http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case http.StatusForbidden:
+		return target == ErrForbidden
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusConflict:
+		return target == ErrConflict
+	case http.StatusTooManyRequests:
+		return target == ErrRateLimited
+	}
+	return false
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    resp.Status,
+		RequestID:  resp.Header.Get(requestIDHeader),
+	}
+	if req := resp.Request; req != nil {
+		apiErr.Method = req.Method
+		apiErr.Path = req.URL.Path
 	}
-	var data struct{ Error struct{ Message string } }
-	err = json.Unmarshal(bs, &data)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return apiErr
+	}
+	apiErr.Body = body
+	if e, err := parseErrorBody(body); err == nil {
+		if e.Message != "" {
+			apiErr.Message = e.Message
+		}
+		apiErr.Fields = e.Fields
+	}
+	return apiErr
+}
+
+type errorBody struct {
+	Message string
+	Fields  []FieldError
+}
+
+// parseErrorBody parses either {"error": {"message": "..."}} or {"error": "..."}.
+// Per-field details are read from "details" in the error object.
+func parseErrorBody(bs []byte) (*errorBody, error) {
+	var data struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(bs, &data); err != nil {
+		return nil, err
+	}
+	var s string
+	if err := json.Unmarshal(data.Error, &s); err == nil {
+		return &errorBody{Message: s}, nil
+	}
+	var obj struct {
+		Message string       `json:"message"`
+		Details []FieldError `json:"details"`
+	}
+	if err := json.Unmarshal(data.Error, &obj); err != nil {
+		return nil, err
 	}
-	return data.Error.Message, nil
+	return &errorBody{Message: obj.Message, Fields: obj.Details}, nil
 }
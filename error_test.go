@@ -0,0 +1,105 @@
+package mackerel
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	sentinels := []error{ErrValidation, ErrUnauthorized, ErrForbidden, ErrNotFound, ErrConflict, ErrRateLimited}
+	tests := []struct {
+		statusCode int
+		want       error
+	}{
+		{http.StatusBadRequest, ErrValidation},
+		{http.StatusUnprocessableEntity, ErrValidation},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusConflict, ErrConflict},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusInternalServerError, nil},
+	}
+	for _, tt := range tests {
+		var err error = &APIError{StatusCode: tt.statusCode}
+		for _, sentinel := range sentinels {
+			if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+				t.Errorf("errors.Is(%d, %v) = %t", tt.statusCode, sentinel, got)
+			}
+		}
+	}
+}
+
+func TestRequest_APIError(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want *APIError
+	}{
+		{
+			name: "object",
+			body: `{"error":{"message":"Monitor not found"}}`,
+			want: &APIError{
+				StatusCode: http.StatusNotFound,
+				Message:    "Monitor not found",
+			},
+		},
+		{
+			name: "string",
+			body: `{"error":"Monitor not found"}`,
+			want: &APIError{
+				StatusCode: http.StatusNotFound,
+				Message:    "Monitor not found",
+			},
+		},
+		{
+			name: "details",
+			body: `{"error":{"message":"invalid payload","details":[{"field":"operator","message":"must be > or <"}]}}`,
+			want: &APIError{
+				StatusCode: http.StatusNotFound,
+				Message:    "invalid payload",
+				Fields:     []FieldError{{Field: "operator", Message: "must be > or <"}},
+			},
+		},
+		{
+			name: "not json",
+			body: `<html>Not Found</html>`,
+			want: &APIError{
+				StatusCode: http.StatusNotFound,
+				Message:    "404 Not Found",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				res.Header().Set("X-Request-Id", "req-1")
+				res.WriteHeader(http.StatusNotFound)
+				res.Write([]byte(tt.body)) // nolint
+			}))
+			defer ts.Close()
+
+			client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+			_, err := client.DeleteMonitorContext(t.Context(), "2cSZzK3XfmG")
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("err should match ErrNotFound but %v", err)
+			}
+			var got *APIError
+			if !errors.As(err, &got) {
+				t.Fatalf("err should be *APIError but %T", err)
+			}
+			want := *tt.want
+			want.Method = http.MethodDelete
+			want.Path = "/api/v0/monitors/2cSZzK3XfmG"
+			want.RequestID = "req-1"
+			want.Body = []byte(tt.body)
+			if diff := pretty.Compare(got, &want); diff != "" {
+				t.Errorf("APIError differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ module github.com/mackerelio/mackerel-client-go
 
 go 1.25.0
 
-require github.com/kylelemons/godebug v1.1.0
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/kylelemons/godebug v1.1.0
+)
 
 retract [v0.37.1, v0.37.2] // A URL containing a username cannot be used with NewClientWithOptions.
 
@@ -25,6 +25,7 @@ func (c *Client) FindGraphAnnotations(service string, from int64, to int64) ([]*
 
 // FindGraphAnnotationsContext fetches graph annotations.
 func (c *Client) FindGraphAnnotationsContext(ctx context.Context, service string, from int64, to int64) ([]*GraphAnnotation, error) {
+	ctx = withOperationName(ctx, "FindGraphAnnotations")
 	params := url.Values{}
 	params.Add("service", service)
 	params.Add("from", strconv.FormatInt(from, 10))
@@ -46,6 +47,7 @@ func (c *Client) CreateGraphAnnotation(annotation *GraphAnnotation) (*GraphAnnot
 
 // CreateGraphAnnotationContext creates a graph annotation.
 func (c *Client) CreateGraphAnnotationContext(ctx context.Context, annotation *GraphAnnotation) (*GraphAnnotation, error) {
+	ctx = withOperationName(ctx, "CreateGraphAnnotation")
 	return requestPostContext[GraphAnnotation](ctx, c, "/api/v0/graph-annotations", annotation)
 }
 
@@ -56,6 +58,7 @@ func (c *Client) UpdateGraphAnnotation(annotationID string, annotation *GraphAnn
 
 // UpdateGraphAnnotationContext updates a graph annotation.
 func (c *Client) UpdateGraphAnnotationContext(ctx context.Context, annotationID string, annotation *GraphAnnotation) (*GraphAnnotation, error) {
+	ctx = withOperationName(ctx, "UpdateGraphAnnotation")
 	path := fmt.Sprintf("/api/v0/graph-annotations/%s", annotationID)
 	return requestPutContext[GraphAnnotation](ctx, c, path, annotation)
 }
@@ -67,6 +70,7 @@ func (c *Client) DeleteGraphAnnotation(annotationID string) (*GraphAnnotation, e
 
 // DeleteGraphAnnotationContext deletes a graph annotation.
 func (c *Client) DeleteGraphAnnotationContext(ctx context.Context, annotationID string) (*GraphAnnotation, error) {
+	ctx = withOperationName(ctx, "DeleteGraphAnnotation")
 	path := fmt.Sprintf("/api/v0/graph-annotations/%s", annotationID)
 	return requestDeleteContext[GraphAnnotation](ctx, c, path)
 }
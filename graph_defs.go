@@ -27,6 +27,7 @@ func (c *Client) CreateGraphDefs(graphDefs []*GraphDefsParam) error {
 
 // CreateGraphDefsContext creates graph definitions.
 func (c *Client) CreateGraphDefsContext(ctx context.Context, graphDefs []*GraphDefsParam) error {
+	ctx = withOperationName(ctx, "CreateGraphDefs")
 	_, err := requestPostContext[any](ctx, c, "/api/v0/graph-defs/create", graphDefs)
 	return err
 }
@@ -38,6 +39,7 @@ func (c *Client) DeleteGraphDef(name string) error {
 
 // DeleteGraphDefContext deletes a graph definition.
 func (c *Client) DeleteGraphDefContext(ctx context.Context, name string) error {
+	ctx = withOperationName(ctx, "DeleteGraphDef")
 	_, err := requestJSON[any](ctx, c, http.MethodDelete, "/api/v0/graph-defs", map[string]string{"name": name})
 	return err
 }
@@ -0,0 +1,277 @@
+package mackerel
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mackerelio/mackerel-client-go/internal/fsutil"
+)
+
+const defaultHostInventoryInterval = time.Minute
+
+// HostSnapshot is the hosts found at a time.
+type HostSnapshot struct {
+	Time  time.Time `json:"time"`
+	Hosts []*Host   `json:"hosts"`
+}
+
+// HostChangeType is the type of a [HostChange].
+type HostChangeType string
+
+// HostChangeTypes
+const (
+	// HostChangeAdded is a host that appeared.
+	HostChangeAdded HostChangeType = "added"
+	// HostChangeRetired is a host that is retired or disappeared.
+	HostChangeRetired HostChangeType = "retired"
+	// HostChangeRoles is a host whose roles changed.
+	HostChangeRoles HostChangeType = "roles"
+	// HostChangeStatus is a host whose status changed.
+	HostChangeStatus HostChangeType = "status"
+	// HostChangeAgentVersion is a host whose agent version changed.
+	HostChangeAgentVersion HostChangeType = "agentVersion"
+	// HostChangeInterfaces is a host whose network interfaces or IP addresses changed.
+	HostChangeInterfaces HostChangeType = "interfaces"
+)
+
+// HostChange is a change of a host between two snapshots.
+type HostChange struct {
+	Type   HostChangeType
+	HostID string
+	// Old and New are the host in the older and the newer snapshots.
+	// Old is nil for HostChangeAdded, and New is nil for hosts disappeared.
+	Old, New *Host
+}
+
+// DiffHostSnapshots returns the changes from old to new, sorted by host ID.
+// old may be nil, and then all the hosts in new are added.
+func DiffHostSnapshots(old, new *HostSnapshot) []*HostChange {
+	oldHosts := make(map[string]*Host)
+	if old != nil {
+		for _, h := range old.Hosts {
+			oldHosts[h.ID] = h
+		}
+	}
+	var changes []*HostChange
+	seen := make(map[string]bool, len(new.Hosts))
+	for _, n := range new.Hosts {
+		seen[n.ID] = true
+		o, ok := oldHosts[n.ID]
+		if !ok {
+			if !n.IsRetired {
+				changes = append(changes, &HostChange{Type: HostChangeAdded, HostID: n.ID, New: n})
+			}
+			continue
+		}
+		if n.IsRetired {
+			if !o.IsRetired {
+				changes = append(changes, &HostChange{Type: HostChangeRetired, HostID: n.ID, Old: o, New: n})
+			}
+			continue
+		}
+		add := func(typ HostChangeType) {
+			changes = append(changes, &HostChange{Type: typ, HostID: n.ID, Old: o, New: n})
+		}
+		if !equalRoles(o.Roles, n.Roles) {
+			add(HostChangeRoles)
+		}
+		if o.Status != n.Status {
+			add(HostChangeStatus)
+		}
+		if o.Meta.AgentVersion != n.Meta.AgentVersion {
+			add(HostChangeAgentVersion)
+		}
+		if !reflect.DeepEqual(normalizeInterfaces(o.Interfaces), normalizeInterfaces(n.Interfaces)) {
+			add(HostChangeInterfaces)
+		}
+	}
+	for id, o := range oldHosts {
+		if !seen[id] && !o.IsRetired {
+			changes = append(changes, &HostChange{Type: HostChangeRetired, HostID: id, Old: o})
+		}
+	}
+	slices.SortStableFunc(changes, func(a, b *HostChange) int {
+		return cmp.Compare(a.HostID, b.HostID)
+	})
+	return changes
+}
+
+func equalRoles(a, b Roles) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for service, roles := range a {
+		other, ok := b[service]
+		if !ok || !slices.Equal(slices.Sorted(slices.Values(roles)), slices.Sorted(slices.Values(other))) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeInterfaces returns the interfaces sorted by name with empty slices as nil.
+func normalizeInterfaces(ifs []Interface) []Interface {
+	ifs = slices.Clone(ifs)
+	for i := range ifs {
+		ifs[i].IPv4Addresses = slices.Sorted(slices.Values(ifs[i].IPv4Addresses))
+		ifs[i].IPv6Addresses = slices.Sorted(slices.Values(ifs[i].IPv6Addresses))
+	}
+	slices.SortFunc(ifs, func(a, b Interface) int { return cmp.Compare(a.Name, b.Name) })
+	return ifs
+}
+
+// HostInventoryStore keeps the latest snapshot of [HostInventory].
+type HostInventoryStore interface {
+	// Load returns the latest snapshot, or nil if there are no snapshots.
+	Load() (*HostSnapshot, error)
+	Save(snapshot *HostSnapshot) error
+}
+
+// FileHostInventoryStore is a HostInventoryStore that keeps the snapshot in a JSON file.
+type FileHostInventoryStore struct {
+	path string
+}
+
+// NewFileHostInventoryStore returns a new FileHostInventoryStore that keeps the snapshot in path.
+func NewFileHostInventoryStore(path string) *FileHostInventoryStore {
+	return &FileHostInventoryStore{path: path}
+}
+
+// Load reads the snapshot from the file. It returns nil if the file does not exist.
+func (s *FileHostInventoryStore) Load() (*HostSnapshot, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var snapshot HostSnapshot
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// Save replaces the file with snapshot atomically.
+func (s *FileHostInventoryStore) Save(snapshot *HostSnapshot) error {
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(s.path, b)
+}
+
+type memoryHostInventoryStore struct {
+	snapshot *HostSnapshot
+}
+
+func (s *memoryHostInventoryStore) Load() (*HostSnapshot, error) { return s.snapshot, nil }
+
+func (s *memoryHostInventoryStore) Save(snapshot *HostSnapshot) error {
+	s.snapshot = snapshot
+	return nil
+}
+
+// HostInventoryOptions configures [HostInventory].
+// Zero-valued fields fall back to their defaults.
+type HostInventoryOptions struct {
+	// Interval is the interval of snapshots taken by Run. The default is 1 minute.
+	Interval time.Duration
+	// Param is the parameter to find hosts. The default finds the hosts of all the statuses,
+	// so that hosts changing to maintenance or poweroff are not taken as retired.
+	// Hosts of the statuses not in Param.Statuses disappear from snapshots and are reported as retired.
+	Param *FindHostsParam
+	// Store keeps the latest snapshot. The default keeps it in memory.
+	Store HostInventoryStore
+	// OnChange, if set, is called with each change in the order of DiffHostSnapshots.
+	OnChange func(*HostChange)
+}
+
+// HostInventory takes snapshots of hosts and tracks their changes.
+// It is safe for concurrent use.
+type HostInventory struct {
+	client *Client
+	opts   HostInventoryOptions
+	now    func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewHostInventory returns a new HostInventory.
+// opts may be nil.
+func NewHostInventory(client *Client, opts *HostInventoryOptions) *HostInventory {
+	var o HostInventoryOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Interval <= 0 {
+		o.Interval = defaultHostInventoryInterval
+	}
+	if o.Param == nil {
+		o.Param = &FindHostsParam{Statuses: []string{
+			HostStatusWorking,
+			HostStatusStandby,
+			HostStatusMaintenance,
+			HostStatusPoweroff,
+		}}
+	}
+	if o.Store == nil {
+		o.Store = &memoryHostInventoryStore{}
+	}
+	return &HostInventory{client: client, opts: o, now: time.Now}
+}
+
+// Snapshot takes a snapshot of hosts, saves it to the store and returns the changes from the previous one.
+// The changes are also passed to OnChange.
+// All the hosts are added in the first snapshot if the store has no snapshots.
+func (inv *HostInventory) Snapshot(ctx context.Context) ([]*HostChange, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	hosts, err := inv.client.FindHostsContext(ctx, inv.opts.Param)
+	if err != nil {
+		return nil, err
+	}
+	prev, err := inv.opts.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &HostSnapshot{Time: inv.now(), Hosts: hosts}
+	changes := DiffHostSnapshots(prev, snapshot)
+	if err := inv.opts.Store.Save(snapshot); err != nil {
+		return nil, err
+	}
+	if inv.opts.OnChange != nil {
+		for _, c := range changes {
+			inv.opts.OnChange(c)
+		}
+	}
+	return changes, nil
+}
+
+// Run takes snapshots every Interval until ctx is done, starting immediately.
+// Failed snapshots are logged and retried at the next interval.
+func (inv *HostInventory) Run(ctx context.Context) error {
+	ticker := time.NewTicker(inv.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := inv.Snapshot(ctx); err != nil && ctx.Err() == nil {
+			if l := inv.client.slogger(); l != nil {
+				l.WarnContext(ctx, "failed to take a snapshot of hosts", slog.String("error", err.Error()))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
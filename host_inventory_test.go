@@ -0,0 +1,114 @@
+package mackerel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDiffHostSnapshots(t *testing.T) {
+	old := &HostSnapshot{Hosts: []*Host{
+		{ID: "a", Status: "working", Roles: Roles{"My-Service": {"web", "db"}}, Meta: HostMeta{AgentVersion: "0.80.0"},
+			Interfaces: []Interface{{Name: "eth0", IPv4Addresses: []string{"10.0.0.1", "10.0.0.2"}}}},
+		{ID: "b", Status: "working", Roles: Roles{"My-Service": {"web"}}},
+		{ID: "c", Status: "working"},
+		{ID: "d", Status: "working"},
+	}}
+	new := &HostSnapshot{Hosts: []*Host{
+		{ID: "a", Status: "working", Roles: Roles{"My-Service": {"db", "web"}}, Meta: HostMeta{AgentVersion: "0.80.0"},
+			Interfaces: []Interface{{Name: "eth0", IPv4Addresses: []string{"10.0.0.2", "10.0.0.1"}, IPv6Addresses: []string{}}}},
+		{ID: "b", Status: "maintenance", Roles: Roles{"My-Service": {"db"}}, Meta: HostMeta{AgentVersion: "0.81.0"},
+			Interfaces: []Interface{{Name: "eth0", IPv4Addresses: []string{"10.0.0.3"}}}},
+		{ID: "c", Status: "working", IsRetired: true},
+		{ID: "e", Status: "standby"},
+	}}
+	var got []string
+	for _, c := range DiffHostSnapshots(old, new) {
+		got = append(got, c.HostID+":"+string(c.Type))
+	}
+	want := []string{"b:roles", "b:status", "b:agentVersion", "b:interfaces", "c:retired", "d:retired", "e:added"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changes should be %v but: %v", want, got)
+	}
+
+	changes := DiffHostSnapshots(nil, new)
+	if len(changes) != 3 || changes[0].Type != HostChangeAdded || changes[0].Old != nil {
+		t.Errorf("all hosts but retired ones should be added: %+v", changes)
+	}
+}
+
+func TestHostInventory(t *testing.T) {
+	status := "working"
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		// Like the API, hosts of maintenance and poweroff are found only when requested.
+		statuses := req.URL.Query()["status"]
+		if len(statuses) == 0 {
+			statuses = []string{HostStatusWorking, HostStatusStandby}
+		}
+		hosts := []*Host{}
+		if slices.Contains(statuses, status) {
+			hosts = append(hosts, &Host{ID: "a", Status: status})
+		}
+		json.NewEncoder(res).Encode(map[string]any{"hosts": hosts}) // nolint
+	}))
+	defer ts.Close()
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+	path := filepath.Join(t.TempDir(), "hosts.json")
+
+	var events []*HostChange
+	inv := NewHostInventory(client, &HostInventoryOptions{
+		Store:    NewFileHostInventoryStore(path),
+		OnChange: func(c *HostChange) { events = append(events, c) },
+	})
+	changes, err := inv.Snapshot(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Type != HostChangeAdded {
+		t.Errorf("the host should be added: %+v", changes)
+	}
+
+	// A new inventory continues from the stored snapshot.
+	status = "standby"
+	inv = NewHostInventory(client, &HostInventoryOptions{
+		Store:    NewFileHostInventoryStore(path),
+		OnChange: func(c *HostChange) { events = append(events, c) },
+	})
+	inv.now = func() time.Time { return time.Unix(1700000000, 0) }
+	changes, err = inv.Snapshot(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Type != HostChangeStatus || changes[0].Old.Status != "working" {
+		t.Errorf("the status should be changed: %+v", changes)
+	}
+	if len(events) != 2 {
+		t.Errorf("changes should be passed to OnChange: %+v", events)
+	}
+	snapshot, err := NewFileHostInventoryStore(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snapshot.Time.Equal(time.Unix(1700000000, 0)) || snapshot.Hosts[0].Status != "standby" {
+		t.Errorf("the latest snapshot should be stored: %+v", snapshot)
+	}
+
+	// Hosts in maintenance are still found.
+	status = "working"
+	if _, err := inv.Snapshot(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	status = "maintenance"
+	changes, err = inv.Snapshot(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Type != HostChangeStatus || changes[0].Old.Status != "working" || changes[0].New.Status != "maintenance" {
+		t.Errorf("the status should be changed rather than retired: %+v", changes)
+	}
+}
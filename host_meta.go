@@ -0,0 +1,307 @@
+package mackerel
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Cloud providers reported by mackerel-agent
+const (
+	CloudProviderEC2   = "ec2"
+	CloudProviderGCE   = "gce"
+	CloudProviderAzure = "AzureVM"
+)
+
+// CPUInfo is a summary of CPU.
+type CPUInfo struct {
+	// LogicalProcessors is the number of the logical processors.
+	LogicalProcessors int
+	// Cores is the number of the physical cores, or LogicalProcessors if it is not reported.
+	Cores int
+	// Model is the model name of the first processor.
+	Model string
+	// MHz is the clock of the first processor.
+	MHz float64
+}
+
+// Info returns the summary of c, whose elements are the logical processors reported by mackerel-agent.
+func (c CPU) Info() CPUInfo {
+	info := CPUInfo{LogicalProcessors: len(c)}
+	if len(c) == 0 {
+		return info
+	}
+	info.Model, _ = c[0]["model_name"].(string)
+	info.MHz, _ = metaFloat(c[0]["mhz"])
+	// Each physical processor reports its number of cores on every logical processor.
+	cores := make(map[string]int)
+	for _, p := range c {
+		n, ok := metaFloat(p["cores"])
+		if !ok {
+			cores = nil
+			break
+		}
+		cores[fmt.Sprint(p["physical_id"])] = int(n)
+	}
+	for _, n := range cores {
+		info.Cores += n
+	}
+	if info.Cores == 0 {
+		info.Cores = info.LogicalProcessors
+	}
+	return info
+}
+
+// Total returns the total memory in bytes.
+func (m Memory) Total() (uint64, bool) {
+	return m.Bytes("total")
+}
+
+// Free returns the free memory in bytes.
+func (m Memory) Free() (uint64, bool) {
+	return m.Bytes("free")
+}
+
+// Bytes returns the value of key, such as "total" or "swap_free", in bytes.
+// The values are formatted like "16384kB" by mackerel-agent, where kB means KiB as in /proc/meminfo.
+// KB, MB, GB and TB are also taken as binary units.
+func (m Memory) Bytes(key string) (uint64, bool) {
+	v, ok := m[key]
+	if !ok {
+		return 0, false
+	}
+	n, err := parseMetaBytes(v)
+	return n, err == nil
+}
+
+// SetBytes sets the value of key to n bytes in the format of mackerel-agent.
+// Values of whole kilobytes are formatted like "16384kB" so that they are read back as they are.
+func (m Memory) SetBytes(key string, n uint64) {
+	if n%1024 == 0 {
+		m[key] = strconv.FormatUint(n/1024, 10) + "kB"
+	} else {
+		m[key] = strconv.FormatUint(n, 10) + "B"
+	}
+}
+
+var metaByteUnits = []struct {
+	suffix string
+	scale  uint64
+}{
+	{"kB", 1 << 10}, {"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"TB", 1 << 40}, {"B", 1},
+}
+
+func parseMetaBytes(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	scale := uint64(1)
+	for _, u := range metaByteUnits {
+		if v, ok := strings.CutSuffix(s, u.suffix); ok {
+			s, scale = strings.TrimSpace(v), u.scale
+			break
+		}
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bytes: %q", s)
+	}
+	return n * scale, nil
+}
+
+// FileSystemUsage is the usage of a file system.
+type FileSystemUsage struct {
+	// Device is the key of the file system in FileSystem, such as "/dev/sda1".
+	Device string
+	Mount  string
+	// Size, Used and Available are in bytes.
+	Size, Used, Available uint64
+}
+
+// Usages returns the usages of the file systems sorted by device.
+func (fs FileSystem) Usages() []FileSystemUsage {
+	usages := make([]FileSystemUsage, 0, len(fs))
+	for _, device := range slices.Sorted(maps.Keys(fs)) {
+		v, ok := fs[device].(map[string]any)
+		if !ok {
+			continue
+		}
+		u := FileSystemUsage{Device: device}
+		u.Mount, _ = v["mount"].(string)
+		u.Size = metaKilobytes(v["kb_size"])
+		u.Used = metaKilobytes(v["kb_used"])
+		u.Available = metaKilobytes(v["kb_available"])
+		usages = append(usages, u)
+	}
+	return usages
+}
+
+// SetUsage sets the usage of the file system of u.Device, keeping the other values of it such as "percent_used".
+// The sizes are stored in kilobytes as mackerel-agent does.
+func (fs FileSystem) SetUsage(u FileSystemUsage) {
+	v, ok := fs[u.Device].(map[string]any)
+	if !ok {
+		v = make(map[string]any)
+		fs[u.Device] = v
+	}
+	v["mount"] = u.Mount
+	v["kb_size"] = u.Size / 1024
+	v["kb_used"] = u.Used / 1024
+	v["kb_available"] = u.Available / 1024
+}
+
+func metaKilobytes(v any) uint64 {
+	n, _ := metaFloat(v)
+	return uint64(n) * 1024
+}
+
+// metaFloat returns v as a number. Numbers in meta are decoded from JSON or reported as strings.
+func metaFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// EC2Metadata is the metadata of Amazon EC2 instances.
+type EC2Metadata struct {
+	InstanceID       string `json:"instance-id,omitempty"`
+	InstanceType     string `json:"instance-type,omitempty"`
+	AvailabilityZone string `json:"placement/availability-zone,omitempty"`
+	AMIID            string `json:"ami-id,omitempty"`
+	Hostname         string `json:"hostname,omitempty"`
+	LocalHostname    string `json:"local-hostname,omitempty"`
+	LocalIPv4        string `json:"local-ipv4,omitempty"`
+	PublicHostname   string `json:"public-hostname,omitempty"`
+	PublicIPv4       string `json:"public-ipv4,omitempty"`
+	MAC              string `json:"mac,omitempty"`
+}
+
+// GCEMetadata is the metadata of Google Compute Engine instances.
+type GCEMetadata struct {
+	ProjectID    string `json:"projectId,omitempty"`
+	InstanceID   string `json:"instanceId,omitempty"`
+	InstanceName string `json:"instanceName,omitempty"`
+	InstanceType string `json:"instanceType,omitempty"`
+	Zone         string `json:"zone,omitempty"`
+	Hostname     string `json:"hostname,omitempty"`
+}
+
+// AzureMetadata is the metadata of Azure virtual machines.
+type AzureMetadata struct {
+	VMID              string `json:"vmId,omitempty"`
+	Name              string `json:"name,omitempty"`
+	VMSize            string `json:"vmSize,omitempty"`
+	Location          string `json:"location,omitempty"`
+	OSType            string `json:"osType,omitempty"`
+	ResourceGroupName string `json:"resourceGroupName,omitempty"`
+	SubscriptionID    string `json:"subscriptionId,omitempty"`
+	PrivateIPAddress  string `json:"privateIpAddress,omitempty"`
+	PublicIPAddress   string `json:"publicIpAddress,omitempty"`
+	MACAddress        string `json:"macAddress,omitempty"`
+}
+
+// EC2 returns the metadata if the provider is CloudProviderEC2.
+func (c *Cloud) EC2() (*EC2Metadata, bool) {
+	return decodeCloudMetadata[EC2Metadata](c, CloudProviderEC2)
+}
+
+// GCE returns the metadata if the provider is CloudProviderGCE.
+func (c *Cloud) GCE() (*GCEMetadata, bool) {
+	return decodeCloudMetadata[GCEMetadata](c, CloudProviderGCE)
+}
+
+// Azure returns the metadata if the provider is CloudProviderAzure.
+func (c *Cloud) Azure() (*AzureMetadata, bool) {
+	return decodeCloudMetadata[AzureMetadata](c, CloudProviderAzure)
+}
+
+// TypedMetadata returns the metadata as *EC2Metadata, *GCEMetadata or *AzureMetadata according to the provider.
+// It returns MetaData as it is for the other providers.
+func (c *Cloud) TypedMetadata() any {
+	if c == nil {
+		return nil
+	}
+	var v any
+	var ok bool
+	switch c.Provider {
+	case CloudProviderEC2:
+		v, ok = c.EC2()
+	case CloudProviderGCE:
+		v, ok = c.GCE()
+	case CloudProviderAzure:
+		v, ok = c.Azure()
+	}
+	if !ok {
+		return c.MetaData
+	}
+	return v
+}
+
+func decodeCloudMetadata[T any](c *Cloud, provider string) (*T, bool) {
+	if c == nil || c.Provider != provider || c.MetaData == nil {
+		return nil, false
+	}
+	b, err := json.Marshal(c.MetaData)
+	if err != nil {
+		return nil, false
+	}
+	var v T
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, false
+	}
+	return &v, true
+}
+
+// SetMetadata merges the fields of v, such as *EC2Metadata, into MetaData.
+// The other keys of MetaData are kept, so that the metadata is sent back as it is.
+func (c *Cloud) SetMetadata(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	md, ok := c.MetaData.(map[string]any)
+	if !ok {
+		if c.MetaData != nil {
+			return fmt.Errorf("metadata is not an object: %T", c.MetaData)
+		}
+		md = make(map[string]any, len(fields))
+	}
+	maps.Copy(md, fields)
+	c.MetaData = md
+	return nil
+}
+
+// CreateHostParam returns the parameter to create or update h with its current values,
+// including Meta as it is.
+func (h *Host) CreateHostParam() *CreateHostParam {
+	roles := h.GetRoleFullnames()
+	slices.Sort(roles)
+	return &CreateHostParam{
+		Name:             h.Name,
+		DisplayName:      h.DisplayName,
+		Memo:             h.Memo,
+		Meta:             h.Meta,
+		Interfaces:       h.Interfaces,
+		RoleFullnames:    roles,
+		CustomIdentifier: h.CustomIdentifier,
+	}
+}
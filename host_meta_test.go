@@ -0,0 +1,81 @@
+package mackerel
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const hostMetaJSON = `{
+	"agent-version": "0.80.0",
+	"cpu": [
+		{"model_name": "Intel(R) Xeon(R) CPU", "mhz": "2400.000", "cores": "2", "physical_id": "0", "core_id": "0"},
+		{"model_name": "Intel(R) Xeon(R) CPU", "mhz": "2400.000", "cores": "2", "physical_id": "0", "core_id": "1"},
+		{"model_name": "Intel(R) Xeon(R) CPU", "mhz": "2400.000", "cores": "2", "physical_id": "0", "core_id": "0"},
+		{"model_name": "Intel(R) Xeon(R) CPU", "mhz": "2400.000", "cores": "2", "physical_id": "0", "core_id": "1"}
+	],
+	"memory": {"total": "16384kB", "free": "8192kB", "swap_total": "0kB"},
+	"filesystem": {
+		"/dev/sda1": {"kb_size": 1024, "kb_used": 256, "kb_available": 768, "mount": "/", "percent_used": "25%"}
+	},
+	"cloud": {
+		"provider": "ec2",
+		"metadata": {"instance-id": "i-0123456789", "instance-type": "t3.micro", "placement/availability-zone": "ap-northeast-1a", "security-groups": "default"}
+	}
+}`
+
+func TestHostMeta(t *testing.T) {
+	var meta HostMeta
+	if err := json.Unmarshal([]byte(hostMetaJSON), &meta); err != nil {
+		t.Fatal(err)
+	}
+	if info := meta.CPU.Info(); info != (CPUInfo{LogicalProcessors: 4, Cores: 2, Model: "Intel(R) Xeon(R) CPU", MHz: 2400}) {
+		t.Errorf("unexpected CPU info: %+v", info)
+	}
+	if total, ok := meta.Memory.Total(); !ok || total != 16384*1024 {
+		t.Errorf("unexpected total memory: %d", total)
+	}
+	if free, ok := meta.Memory.Free(); !ok || free != 8192*1024 {
+		t.Errorf("unexpected free memory: %d", free)
+	}
+	if _, ok := meta.Memory.Bytes("cached"); ok {
+		t.Error("missing memory values should not be ok")
+	}
+	want := []FileSystemUsage{{Device: "/dev/sda1", Mount: "/", Size: 1024 * 1024, Used: 256 * 1024, Available: 768 * 1024}}
+	if usages := meta.Filesystem.Usages(); !reflect.DeepEqual(usages, want) {
+		t.Errorf("unexpected usages: %+v", usages)
+	}
+	ec2, ok := meta.Cloud.EC2()
+	if !ok || ec2.InstanceID != "i-0123456789" || ec2.AvailabilityZone != "ap-northeast-1a" {
+		t.Errorf("unexpected EC2 metadata: %+v", ec2)
+	}
+	if _, ok := meta.Cloud.GCE(); ok {
+		t.Error("GCE metadata should not be returned for EC2")
+	}
+	if _, ok := meta.Cloud.TypedMetadata().(*EC2Metadata); !ok {
+		t.Errorf("unexpected typed metadata: %T", meta.Cloud.TypedMetadata())
+	}
+
+	// Setting the values read does not change the meta.
+	total, _ := meta.Memory.Total()
+	meta.Memory.SetBytes("total", total)
+	meta.Filesystem.SetUsage(meta.Filesystem.Usages()[0])
+	if err := meta.Cloud.SetMetadata(ec2); err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal((&Host{Meta: meta}).CreateHostParam().Meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalJSON(string(b), hostMetaJSON) {
+		t.Errorf("meta should be round-tripped but: %s", b)
+	}
+
+	ec2.InstanceType = "t3.small"
+	if err := meta.Cloud.SetMetadata(ec2); err != nil {
+		t.Fatal(err)
+	}
+	if md := meta.Cloud.MetaData.(map[string]any); md["instance-type"] != "t3.small" || md["security-groups"] != "default" {
+		t.Errorf("metadata should be merged: %+v", md)
+	}
+}
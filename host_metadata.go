@@ -25,6 +25,7 @@ func (c *Client) GetHostMetaData(hostID, namespace string) (*HostMetaDataResp, e
 
 // GetHostMetaDataContext gets a host metadata.
 func (c *Client) GetHostMetaDataContext(ctx context.Context, hostID, namespace string) (*HostMetaDataResp, error) {
+	ctx = withOperationName(ctx, "GetHostMetaData")
 	path := fmt.Sprintf("/api/v0/hosts/%s/metadata/%s", hostID, namespace)
 	metadata, header, err := requestGetAndReturnHeaderContext[HostMetaData](ctx, c, path)
 	if err != nil {
@@ -44,6 +45,7 @@ func (c *Client) GetHostMetaDataNameSpaces(hostID string) ([]string, error) {
 
 // GetHostMetaDataNameSpacesContext fetches namespaces of host metadata.
 func (c *Client) GetHostMetaDataNameSpacesContext(ctx context.Context, hostID string) ([]string, error) {
+	ctx = withOperationName(ctx, "GetHostMetaDataNameSpaces")
 	data, err := requestGetContext[struct {
 		MetaDatas []struct {
 			NameSpace string `json:"namespace"`
@@ -66,6 +68,7 @@ func (c *Client) PutHostMetaData(hostID, namespace string, metadata HostMetaData
 
 // PutHostMetaDataContext puts a host metadata.
 func (c *Client) PutHostMetaDataContext(ctx context.Context, hostID, namespace string, metadata HostMetaData) error {
+	ctx = withOperationName(ctx, "PutHostMetaData")
 	path := fmt.Sprintf("/api/v0/hosts/%s/metadata/%s", hostID, namespace)
 	_, err := requestPutContext[any](ctx, c, path, metadata)
 	return err
@@ -78,6 +81,7 @@ func (c *Client) DeleteHostMetaData(hostID, namespace string) error {
 
 // DeleteHostMetaDataContext deletes a host metadata.
 func (c *Client) DeleteHostMetaDataContext(ctx context.Context, hostID, namespace string) error {
+	ctx = withOperationName(ctx, "DeleteHostMetaData")
 	path := fmt.Sprintf("/api/v0/hosts/%s/metadata/%s", hostID, namespace)
 	_, err := requestDeleteContext[any](ctx, c, path)
 	return err
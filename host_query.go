@@ -0,0 +1,671 @@
+package mackerel
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// HostQuery is a compiled query that filters hosts on the client side.
+//
+// A query combines comparisons of fields of hosts with "and", "or", "not" and parentheses:
+//
+//	agent-version < 0.80 and cloud.provider == "ec2" and memory.total > 8GiB and not retired
+//
+// The operators are ==, !=, <, <=, >, >= and =~, which matches a regular expression.
+// Strings are quoted by double quotes, or may be bare words such as working.
+// Numbers may have a unit of bytes: B, the decimal KB, MB, GB and TB of 1000^n bytes,
+// or the binary KiB, MiB, GiB and TiB of 1024^n bytes. Spaces are allowed before the unit, as in 8 GiB.
+// Numeric fields only accept numbers, and fields other than retired need a comparison.
+//
+// The fields are:
+//
+//   - id, name, displayName, customIdentifier, memo, size and status
+//   - retired, which is a boolean and can be used without comparison
+//   - createdAt, in epoch seconds
+//   - service and role, such as "My-Service: db", which match if any of the services or the roles of the host matches
+//   - ip, which matches if any of the addresses of the interfaces matches
+//   - agent-version, compared as versions, agent-name and agent-revision
+//   - cpu.cores, cpu.count, the number of logical processors, cpu.model and cpu.mhz
+//   - memory.total, memory.free and the other keys of memory in bytes. The values reported
+//     by mackerel-agent, such as "16384kB", are in KiB, so memory.total == 16MiB matches them.
+//   - kernel.name, kernel.release and the other keys of kernel
+//   - cloud.provider and cloud.<key> for the keys of the cloud metadata, such as cloud.instance-type
+//
+// Comparisons with fields that a host does not have are false, except for !=.
+type HostQuery struct {
+	src  string
+	expr hostQueryExpr
+}
+
+// ParseHostQuery compiles a query of hosts.
+func ParseHostQuery(s string) (*HostQuery, error) {
+	tokens, err := lexHostQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &hostQueryParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != hostQueryEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	return &HostQuery{src: s, expr: expr}, nil
+}
+
+// String returns the source of q.
+func (q *HostQuery) String() string {
+	return q.src
+}
+
+// Match reports whether h matches q.
+func (q *HostQuery) Match(h *Host) bool {
+	return q.expr.eval(h)
+}
+
+// Filter returns the hosts matching q.
+func (q *HostQuery) Filter(hosts []*Host) []*Host {
+	var matched []*Host
+	for _, h := range hosts {
+		if q.Match(h) {
+			matched = append(matched, h)
+		}
+	}
+	return matched
+}
+
+// QueryHostsParam parameters for QueryHosts
+type QueryHostsParam struct {
+	// Find is the parameter to find the hosts to filter. The default finds the hosts of the default statuses.
+	Find *FindHostsParam
+	// Query filters the hosts found. See [HostQuery] for the syntax. Empty query matches all the hosts.
+	Query string
+	// SortBy is the fields of [HostQuery] to sort the hosts by, such as "name".
+	// A field prefixed with "-" sorts in descending order. Hosts without the field come last.
+	SortBy []string
+}
+
+// QueryHosts finds hosts and filters them by a query.
+func (c *Client) QueryHosts(param *QueryHostsParam) ([]*Host, error) {
+	return c.QueryHostsContext(context.Background(), param)
+}
+
+// QueryHostsContext finds hosts by FindHostsContext and filters them by a query.
+func (c *Client) QueryHostsContext(ctx context.Context, param *QueryHostsParam) ([]*Host, error) {
+	q := &HostQuery{expr: hostQueryTrue{}}
+	if param.Query != "" {
+		var err error
+		if q, err = ParseHostQuery(param.Query); err != nil {
+			return nil, err
+		}
+	}
+	find := param.Find
+	if find == nil {
+		find = &FindHostsParam{}
+	}
+	hosts, err := c.FindHostsContext(ctx, find)
+	if err != nil {
+		return nil, err
+	}
+	hosts = q.Filter(hosts)
+	SortHosts(hosts, param.SortBy...)
+	return hosts, nil
+}
+
+// SortHosts sorts hosts stably by the fields of [HostQuery], such as "name" or "-memory.total".
+// A field prefixed with "-" sorts in descending order. Hosts without the field come last.
+func SortHosts(hosts []*Host, fields ...string) {
+	if len(fields) == 0 {
+		return
+	}
+	slices.SortStableFunc(hosts, func(a, b *Host) int {
+		for _, f := range fields {
+			name, desc := strings.CutPrefix(f, "-")
+			va, oka := hostQueryField(a, name)
+			vb, okb := hostQueryField(b, name)
+			switch {
+			case !oka && !okb:
+				continue
+			case !oka:
+				return 1
+			case !okb:
+				return -1
+			}
+			c := va.compare(vb)
+			if desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+// hostQueryValue is a value of a field of hosts.
+type hostQueryValue struct {
+	kind hostQueryKind
+	str  string
+	num  float64
+	list []string
+}
+
+type hostQueryKind int
+
+const (
+	hostQueryString hostQueryKind = iota
+	hostQueryNumber
+	hostQueryBoolean
+	hostQueryVersion
+	hostQueryList
+)
+
+func (v hostQueryValue) compare(w hostQueryValue) int {
+	switch v.kind {
+	case hostQueryNumber, hostQueryBoolean:
+		return cmp.Compare(v.num, w.num)
+	case hostQueryVersion:
+		return compareVersions(v.str, w.str)
+	case hostQueryList:
+		return slices.Compare(v.list, w.list)
+	}
+	return strings.Compare(v.str, w.str)
+}
+
+func hostQueryBool(b bool) hostQueryValue {
+	v := hostQueryValue{kind: hostQueryBoolean}
+	if b {
+		v.num = 1
+	}
+	return v
+}
+
+func hostQueryField(h *Host, name string) (hostQueryValue, bool) {
+	str := func(s string) (hostQueryValue, bool) {
+		return hostQueryValue{kind: hostQueryString, str: s}, s != ""
+	}
+	num := func(n float64, ok bool) (hostQueryValue, bool) {
+		return hostQueryValue{kind: hostQueryNumber, num: n}, ok
+	}
+	switch name {
+	case "id":
+		return str(h.ID)
+	case "name":
+		return str(h.Name)
+	case "displayName":
+		return str(h.DisplayName)
+	case "customIdentifier":
+		return str(h.CustomIdentifier)
+	case "memo":
+		return str(h.Memo)
+	case "size":
+		return str(h.Size)
+	case "status":
+		return str(h.Status)
+	case "retired":
+		return hostQueryBool(h.IsRetired), true
+	case "createdAt":
+		return num(float64(h.CreatedAt), true)
+	case "service":
+		services := make([]string, 0, len(h.Roles))
+		for s := range h.Roles {
+			services = append(services, s)
+		}
+		slices.Sort(services)
+		return hostQueryValue{kind: hostQueryList, list: services}, true
+	case "role":
+		roles := h.GetRoleFullnames()
+		slices.Sort(roles)
+		return hostQueryValue{kind: hostQueryList, list: roles}, true
+	case "ip":
+		var ips []string
+		for _, i := range h.Interfaces {
+			if i.IPAddress != "" {
+				ips = append(ips, i.IPAddress)
+			}
+			ips = append(ips, i.IPv4Addresses...)
+			ips = append(ips, i.IPv6Addresses...)
+		}
+		return hostQueryValue{kind: hostQueryList, list: ips}, true
+	case "agent-version":
+		return hostQueryValue{kind: hostQueryVersion, str: h.Meta.AgentVersion}, h.Meta.AgentVersion != ""
+	case "agent-name":
+		return str(h.Meta.AgentName)
+	case "agent-revision":
+		return str(h.Meta.AgentRevision)
+	case "cpu.cores":
+		return num(float64(h.Meta.CPU.Info().Cores), len(h.Meta.CPU) > 0)
+	case "cpu.count":
+		return num(float64(len(h.Meta.CPU)), len(h.Meta.CPU) > 0)
+	case "cpu.model":
+		return str(h.Meta.CPU.Info().Model)
+	case "cpu.mhz":
+		info := h.Meta.CPU.Info()
+		return num(info.MHz, info.MHz != 0)
+	case "cloud.provider":
+		if h.Meta.Cloud == nil {
+			return str("")
+		}
+		return str(h.Meta.Cloud.Provider)
+	}
+	if key, ok := strings.CutPrefix(name, "memory."); ok {
+		n, ok := h.Meta.Memory.Bytes(key)
+		return num(float64(n), ok)
+	}
+	if key, ok := strings.CutPrefix(name, "kernel."); ok {
+		return str(h.Meta.Kernel[key])
+	}
+	if key, ok := strings.CutPrefix(name, "cloud."); ok && h.Meta.Cloud != nil {
+		md, _ := h.Meta.Cloud.MetaData.(map[string]any)
+		switch v := md[key].(type) {
+		case string:
+			return str(v)
+		case float64:
+			return num(v, true)
+		case bool:
+			return hostQueryBool(v), true
+		}
+	}
+	return hostQueryValue{}, false
+}
+
+// compareVersions compares versions such as "0.80.1" by their numeric parts.
+func compareVersions(a, b string) int {
+	pa := strings.FieldsFunc(strings.TrimPrefix(a, "v"), isVersionSeparator)
+	pb := strings.FieldsFunc(strings.TrimPrefix(b, "v"), isVersionSeparator)
+	for i := range max(len(pa), len(pb)) {
+		var x, y string
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		nx, errx := strconv.Atoi(cmp.Or(x, "0"))
+		ny, erry := strconv.Atoi(cmp.Or(y, "0"))
+		var c int
+		if errx == nil && erry == nil {
+			c = cmp.Compare(nx, ny)
+		} else {
+			c = strings.Compare(x, y)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func isVersionSeparator(r rune) bool {
+	return r == '.' || r == '-' || r == '+'
+}
+
+type hostQueryExpr interface {
+	eval(h *Host) bool
+}
+
+type hostQueryTrue struct{}
+
+func (hostQueryTrue) eval(*Host) bool { return true }
+
+type hostQueryAnd struct{ left, right hostQueryExpr }
+
+func (e hostQueryAnd) eval(h *Host) bool { return e.left.eval(h) && e.right.eval(h) }
+
+type hostQueryOr struct{ left, right hostQueryExpr }
+
+func (e hostQueryOr) eval(h *Host) bool { return e.left.eval(h) || e.right.eval(h) }
+
+type hostQueryNot struct{ expr hostQueryExpr }
+
+func (e hostQueryNot) eval(h *Host) bool { return !e.expr.eval(h) }
+
+type hostQueryComparison struct {
+	field string
+	op    string
+	// value is the literal in the query, and re is compiled from it for =~.
+	value string
+	re    *regexp.Regexp
+}
+
+func (e *hostQueryComparison) eval(h *Host) bool {
+	v, ok := hostQueryField(h, e.field)
+	if !ok {
+		return e.op == "!="
+	}
+	if e.op == "=~" {
+		if v.kind == hostQueryList {
+			return slices.ContainsFunc(v.list, e.re.MatchString)
+		}
+		return e.re.MatchString(v.str)
+	}
+	var c int
+	switch v.kind {
+	case hostQueryList:
+		found := slices.ContainsFunc(v.list, func(s string) bool { return normalizeHostQueryList(s) == normalizeHostQueryList(e.value) })
+		switch e.op {
+		case "==":
+			return found
+		case "!=":
+			return !found
+		}
+		return false
+	case hostQueryNumber, hostQueryBoolean:
+		n, err := parseHostQueryNumber(e.value)
+		if err != nil {
+			return false
+		}
+		c = cmp.Compare(v.num, n)
+	case hostQueryVersion:
+		c = compareVersions(v.str, e.value)
+	default:
+		c = strings.Compare(v.str, e.value)
+	}
+	switch e.op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+// normalizeHostQueryList makes "My-Service:db" equal to "My-Service: db".
+func normalizeHostQueryList(s string) string {
+	if scope, err := ParseMonitorScope(s); err == nil {
+		return scope.String()
+	}
+	return s
+}
+
+// hostQueryByteUnits are the units of numbers in queries. Unlike the values of meta,
+// where kB means KiB, KB and the other units without "i" are decimal.
+var hostQueryByteUnits = map[string]float64{
+	"B":  1,
+	"KB": 1e3, "MB": 1e6, "GB": 1e9, "TB": 1e12,
+	"KiB": 1 << 10, "MiB": 1 << 20, "GiB": 1 << 30, "TiB": 1 << 40,
+}
+
+func parseHostQueryNumber(s string) (float64, error) {
+	switch s {
+	case "true":
+		return 1, nil
+	case "false":
+		return 0, nil
+	}
+	i := strings.IndexFunc(s, unicode.IsLetter)
+	if i < 0 {
+		return strconv.ParseFloat(s, 64)
+	}
+	unit, ok := hostQueryByteUnits[s[i:]]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit: %q", s[i:])
+	}
+	n, err := strconv.ParseFloat(s[:i], 64)
+	return n * unit, err
+}
+
+type hostQueryTokenKind int
+
+const (
+	hostQueryEOF hostQueryTokenKind = iota
+	hostQueryIdent
+	hostQueryLiteral
+	hostQueryNumberLiteral
+	hostQueryOperator
+	hostQueryLParen
+	hostQueryRParen
+)
+
+type hostQueryToken struct {
+	kind hostQueryTokenKind
+	text string
+	pos  int
+}
+
+var hostQueryOperators = []string{"==", "!=", "<=", ">=", "=~", "&&", "||", "<", ">", "!"}
+
+func lexHostQuery(s string) ([]hostQueryToken, error) {
+	var tokens []hostQueryToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, hostQueryToken{hostQueryLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, hostQueryToken{hostQueryRParen, ")", i})
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("invalid host query at %d: unterminated string", i)
+			}
+			text, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid host query at %d: %w", i, err)
+			}
+			tokens = append(tokens, hostQueryToken{hostQueryLiteral, text, i})
+			i = j + 1
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(s) && (isHostQueryIdentByte(s[j]) && s[j] != '-') {
+				j++
+			}
+			tokens = append(tokens, hostQueryToken{hostQueryNumberLiteral, s[i:j], i})
+			i = j
+		case isHostQueryIdentByte(c):
+			j := i
+			for j < len(s) && isHostQueryIdentByte(s[j]) {
+				j++
+			}
+			tokens = append(tokens, hostQueryToken{hostQueryIdent, s[i:j], i})
+			i = j
+		default:
+			op := ""
+			for _, o := range hostQueryOperators {
+				if strings.HasPrefix(s[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("invalid host query at %d: unexpected %q", i, c)
+			}
+			tokens = append(tokens, hostQueryToken{hostQueryOperator, op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, hostQueryToken{hostQueryEOF, "", len(s)}), nil
+}
+
+func isHostQueryIdentByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.' || c == '/'
+}
+
+type hostQueryParser struct {
+	tokens []hostQueryToken
+	pos    int
+}
+
+func (p *hostQueryParser) peek() hostQueryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *hostQueryParser) next() hostQueryToken {
+	t := p.tokens[p.pos]
+	if t.kind != hostQueryEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *hostQueryParser) errorf(t hostQueryToken, format string, v ...any) error {
+	return fmt.Errorf("invalid host query at %d: %s", t.pos, fmt.Sprintf(format, v...))
+}
+
+func (p *hostQueryParser) isKeyword(t hostQueryToken, keyword, op string) bool {
+	return t.kind == hostQueryIdent && strings.EqualFold(t.text, keyword) || t.kind == hostQueryOperator && t.text == op
+}
+
+func (p *hostQueryParser) parseOr() (hostQueryExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword(p.peek(), "or", "||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = hostQueryOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *hostQueryParser) parseAnd() (hostQueryExpr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword(p.peek(), "and", "&&") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = hostQueryAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *hostQueryParser) parseNot() (hostQueryExpr, error) {
+	if p.isKeyword(p.peek(), "not", "!") {
+		p.next()
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return hostQueryNot{expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *hostQueryParser) parsePrimary() (hostQueryExpr, error) {
+	t := p.next()
+	switch t.kind {
+	case hostQueryLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if r := p.next(); r.kind != hostQueryRParen {
+			return nil, p.errorf(r, "expected \")\" but %q", r.text)
+		}
+		return expr, nil
+	case hostQueryIdent:
+	default:
+		return nil, p.errorf(t, "expected a field but %q", t.text)
+	}
+	kind, ok := hostQueryFieldKind(t.text)
+	if !ok {
+		return nil, p.errorf(t, "unknown field %q", t.text)
+	}
+	op := p.peek()
+	if op.kind != hostQueryOperator || op.text == "!" || op.text == "&&" || op.text == "||" {
+		// A field without comparison is true if it is a true boolean.
+		if kind != hostQueryBoolean {
+			return nil, p.errorf(t, "field %q is not a boolean and needs a comparison", t.text)
+		}
+		return &hostQueryComparison{field: t.text, op: "==", value: "true"}, nil
+	}
+	p.next()
+	v := p.next()
+	if v.kind != hostQueryLiteral && v.kind != hostQueryNumberLiteral && v.kind != hostQueryIdent {
+		return nil, p.errorf(v, "expected a value but %q", v.text)
+	}
+	value := v.text
+	// A unit may follow a number after spaces, such as 8 GiB.
+	if u := p.peek(); v.kind == hostQueryNumberLiteral && u.kind == hostQueryIdent && hostQueryByteUnits[u.text] != 0 {
+		p.next()
+		value += u.text
+	}
+	e := &hostQueryComparison{field: t.text, op: op.text, value: value}
+	if e.op == "=~" {
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, p.errorf(v, "%v", err)
+		}
+		e.re = re
+	}
+	switch {
+	case e.op == "=~":
+	case kind == hostQueryNumber:
+		if v.kind == hostQueryIdent {
+			return nil, p.errorf(v, "expected a number for field %q but %q", t.text, value)
+		}
+		if _, err := parseHostQueryNumber(value); err != nil {
+			return nil, p.errorf(v, "%v", err)
+		}
+	case kind == hostQueryBoolean:
+		if value != "true" && value != "false" {
+			return nil, p.errorf(v, "expected true or false for field %q but %q", t.text, value)
+		}
+	// Numbers with more than one dot are versions such as 0.80.1.
+	case v.kind == hostQueryNumberLiteral && strings.Count(value, ".") < 2:
+		if _, err := parseHostQueryNumber(value); err != nil {
+			return nil, p.errorf(v, "%v", err)
+		}
+	}
+	return e, nil
+}
+
+// hostQueryFieldKind returns the kind of the values of the field of HostQuery, and whether name is a field.
+func hostQueryFieldKind(name string) (hostQueryKind, bool) {
+	if strings.HasPrefix(name, "memory.") && len(name) > len("memory.") {
+		return hostQueryNumber, true
+	}
+	for _, prefix := range []string{"kernel.", "cloud."} {
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			return hostQueryString, true
+		}
+	}
+	switch name {
+	case "id", "name", "displayName", "customIdentifier", "memo", "size", "status",
+		"agent-name", "agent-revision", "cpu.model":
+		return hostQueryString, true
+	case "retired":
+		return hostQueryBoolean, true
+	case "createdAt", "cpu.cores", "cpu.count", "cpu.mhz":
+		return hostQueryNumber, true
+	case "service", "role", "ip":
+		return hostQueryList, true
+	case "agent-version":
+		return hostQueryVersion, true
+	}
+	return 0, false
+}
@@ -0,0 +1,105 @@
+package mackerel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHostQuery(t *testing.T) {
+	var meta HostMeta
+	if err := json.Unmarshal([]byte(hostMetaJSON), &meta); err != nil {
+		t.Fatal(err)
+	}
+	host := &Host{
+		ID: "a", Name: "web01", Status: "working", Meta: meta,
+		Roles:      Roles{"My-Service": {"web"}},
+		Interfaces: []Interface{{Name: "eth0", IPv4Addresses: []string{"10.0.0.1"}}},
+	}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{`agent-version < 0.80.1 and cloud.provider == "ec2" and memory.total > 8MiB and not retired`, true},
+		{`agent-version < 0.80`, false},
+		{`agent-version >= 0.79.10`, true},
+		{`memory.total == 16MiB`, true},
+		{`memory.free > 16MB`, false},
+		{`memory.total > 16MB and memory.total < 17MB`, true},
+		{`memory.total == 16384KiB and memory.total == 16777216B`, true},
+		{`cpu.cores == 2 && cpu.count == 4`, true},
+		{`status == working`, true},
+		{`status == standby or name =~ "^web"`, true},
+		{`!(status == standby || name =~ "^db")`, true},
+		{`role == "My-Service:web"`, true},
+		{`role != "My-Service: db" and service == My-Service`, true},
+		{`ip == 10.0.0.1`, true},
+		{`cloud.instance-type == t3.micro and cloud.placement/availability-zone =~ "^ap-"`, true},
+		{`retired`, false},
+		{`displayName == ""`, false},
+		{`displayName != web01`, true},
+		{`kernel.release == "6.0"`, false},
+		{`memory.total == 16 MiB and retired == false`, true},
+	}
+	for _, tt := range tests {
+		q, err := ParseHostQuery(tt.query)
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		if got := q.Match(host); got != tt.want {
+			t.Errorf("%s: should be %t", tt.query, tt.want)
+		}
+	}
+
+	for _, query := range []string{
+		``, `name ==`, `(name == a`, `name == a)`, `unknown == a`, `memory.total > 8XB`, `name =~ "("`, `name == "a`, `name = a`,
+		`memory.total > foo`, `name`, `name and retired`, `retired == yes`, `memory.total > 8 XB`,
+	} {
+		if _, err := ParseHostQuery(query); err == nil {
+			t.Errorf("%q should be invalid", query)
+		}
+	}
+	if _, err := ParseHostQuery(`retired and memory.total > foo`); err == nil || !strings.Contains(err.Error(), "at 27:") {
+		t.Errorf("the error should have the position of the value but: %v", err)
+	}
+}
+
+func TestQueryHosts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if got := req.URL.Query().Get("service"); got != "My-Service" {
+			t.Errorf("service should be passed but: %q", got)
+		}
+		json.NewEncoder(res).Encode(map[string]any{"hosts": []*Host{ // nolint
+			{ID: "a", Meta: HostMeta{AgentVersion: "0.79.0", Memory: Memory{"total": "4194304kB"}}},
+			{ID: "b", Meta: HostMeta{AgentVersion: "0.81.0", Memory: Memory{"total": "16777216kB"}}},
+			{ID: "c", Meta: HostMeta{AgentVersion: "0.80.0"}},
+			{ID: "d", Meta: HostMeta{AgentVersion: "0.82.0", Memory: Memory{"total": "8388608kB"}}},
+		}})
+	}))
+	defer ts.Close()
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+
+	hosts, err := client.QueryHosts(&QueryHostsParam{
+		Find:   &FindHostsParam{Service: "My-Service"},
+		Query:  "agent-version >= 0.80",
+		SortBy: []string{"-memory.total"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, h := range hosts {
+		ids = append(ids, h.ID)
+	}
+	if want := []string{"b", "d", "c"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("hosts should be %v but: %v", want, ids)
+	}
+
+	if _, err := client.QueryHosts(&QueryHostsParam{Query: "name =="}); err == nil {
+		t.Error("invalid query should fail")
+	}
+}
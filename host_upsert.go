@@ -0,0 +1,78 @@
+package mackerel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// UpsertHostOptions configures UpsertHostByCustomIdentifier.
+type UpsertHostOptions struct {
+	// MergeRoles adds RoleFullnames of the parameter to the roles of an existing host instead of replacing them.
+	MergeRoles bool
+}
+
+// UpsertHostResult is the result of UpsertHostByCustomIdentifier.
+type UpsertHostResult struct {
+	HostID string
+	// Created reports whether the host is created, or an existing host is updated.
+	Created bool
+}
+
+// UpsertHostByCustomIdentifier creates a host, or updates the host of the same custom identifier.
+func (c *Client) UpsertHostByCustomIdentifier(param *CreateHostParam, opts *UpsertHostOptions) (*UpsertHostResult, error) {
+	return c.UpsertHostByCustomIdentifierContext(context.Background(), param, opts)
+}
+
+// UpsertHostByCustomIdentifierContext creates a host, or updates the host of the same custom identifier.
+// param.CustomIdentifier is required. opts may be nil.
+//
+// If another process creates the host of the custom identifier at the same time, the host is updated instead.
+func (c *Client) UpsertHostByCustomIdentifierContext(ctx context.Context, param *CreateHostParam, opts *UpsertHostOptions) (*UpsertHostResult, error) {
+	if param.CustomIdentifier == "" {
+		return nil, &ValidationError{Fields: []FieldError{{Field: "customIdentifier", Message: "is required"}}}
+	}
+	var o UpsertHostOptions
+	if opts != nil {
+		o = *opts
+	}
+	host, err := c.FindHostByCustomIdentifierContext(ctx, param.CustomIdentifier, &FindHostByCustomIdentifierParam{})
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		id, err := c.CreateHostContext(ctx, param)
+		if err == nil {
+			return &UpsertHostResult{HostID: id, Created: true}, nil
+		}
+		if !errors.Is(err, ErrConflict) {
+			return nil, err
+		}
+		// Another process created the host after it was not found.
+		if host, err = c.FindHostByCustomIdentifierContext(ctx, param.CustomIdentifier, &FindHostByCustomIdentifierParam{}); err != nil {
+			return nil, fmt.Errorf("failed to find the host created concurrently: %w", err)
+		}
+	}
+	if err := c.updateUpsertedHost(ctx, host, param, o); err != nil {
+		return nil, err
+	}
+	return &UpsertHostResult{HostID: host.ID}, nil
+}
+
+func (c *Client) updateUpsertedHost(ctx context.Context, host *Host, param *CreateHostParam, opts UpsertHostOptions) error {
+	if !opts.MergeRoles {
+		_, err := c.UpdateHostContext(ctx, host.ID, (*UpdateHostParam)(param))
+		return err
+	}
+	roles := slices.Sorted(slices.Values(append(host.GetRoleFullnames(), param.RoleFullnames...)))
+	roles = slices.Compact(roles)
+
+	// Roles are set after the update of the host so that they are merged even if the update replaces them.
+	p := UpdateHostParam(*param)
+	p.RoleFullnames = nil
+	if _, err := c.UpdateHostContext(ctx, host.ID, &p); err != nil {
+		return err
+	}
+	return c.UpdateHostRoleFullnamesContext(ctx, host.ID, roles)
+}
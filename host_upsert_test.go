@@ -0,0 +1,113 @@
+package mackerel
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestUpsertHostByCustomIdentifier(t *testing.T) {
+	tests := []struct {
+		name       string
+		existing   *Host
+		conflict   bool
+		opts       *UpsertHostOptions
+		wantResult UpsertHostResult
+		wantUpdate []string
+		wantRoles  []string
+	}{
+		{
+			name:       "create",
+			wantResult: UpsertHostResult{HostID: "new", Created: true},
+		},
+		{
+			name:       "update",
+			existing:   &Host{ID: "old", CustomIdentifier: "vm-1", Roles: Roles{"My-Service": {"db"}}},
+			wantResult: UpsertHostResult{HostID: "old"},
+			wantUpdate: []string{"My-Service:web"},
+		},
+		{
+			name:       "merge roles",
+			existing:   &Host{ID: "old", CustomIdentifier: "vm-1", Roles: Roles{"My-Service": {"db", "web"}}},
+			opts:       &UpsertHostOptions{MergeRoles: true},
+			wantResult: UpsertHostResult{HostID: "old"},
+			wantRoles:  []string{"My-Service:db", "My-Service:web"},
+		},
+		{
+			name:       "created concurrently",
+			existing:   &Host{ID: "other", CustomIdentifier: "vm-1"},
+			conflict:   true,
+			wantResult: UpsertHostResult{HostID: "other"},
+			wantUpdate: []string{"My-Service:web"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var found bool
+			var update *UpdateHostParam
+			var roles []string
+			ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				switch req.Method + " " + req.URL.Path {
+				case "GET /api/v0/hosts-by-custom-identifier/vm-1":
+					// The host is not found at first if it is created concurrently.
+					if tt.existing == nil || tt.conflict && !found {
+						found = true
+						res.WriteHeader(http.StatusNotFound)
+						return
+					}
+					json.NewEncoder(res).Encode(map[string]any{"host": tt.existing}) // nolint
+				case "POST /api/v0/hosts":
+					if tt.conflict {
+						res.WriteHeader(http.StatusConflict)
+						return
+					}
+					json.NewEncoder(res).Encode(map[string]string{"id": "new"}) // nolint
+				case "PUT /api/v0/hosts/" + tt.wantResult.HostID:
+					json.NewDecoder(req.Body).Decode(&update)                            // nolint
+					json.NewEncoder(res).Encode(map[string]string{"id": tt.existing.ID}) // nolint
+				case "PUT /api/v0/hosts/" + tt.wantResult.HostID + "/role-fullnames":
+					var body struct {
+						RoleFullnames []string `json:"roleFullnames"`
+					}
+					json.NewDecoder(req.Body).Decode(&body) // nolint
+					roles = body.RoleFullnames
+					json.NewEncoder(res).Encode(map[string]bool{"success": true}) // nolint
+				default:
+					t.Errorf("unexpected request: %s %s", req.Method, req.URL.Path)
+					res.WriteHeader(http.StatusInternalServerError)
+				}
+			}))
+			defer ts.Close()
+			client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+
+			result, err := client.UpsertHostByCustomIdentifier(&CreateHostParam{
+				Name:             "vm-1",
+				CustomIdentifier: "vm-1",
+				RoleFullnames:    []string{"My-Service:web"},
+			}, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *result != tt.wantResult {
+				t.Errorf("result should be %+v but: %+v", tt.wantResult, result)
+			}
+			if tt.wantUpdate != nil && (update == nil || !reflect.DeepEqual(update.RoleFullnames, tt.wantUpdate)) {
+				t.Errorf("the host should be updated with roles %v but: %+v", tt.wantUpdate, update)
+			}
+			if tt.opts != nil && tt.opts.MergeRoles && (update == nil || update.RoleFullnames != nil) {
+				t.Errorf("the update should keep the roles: %+v", update)
+			}
+			if !reflect.DeepEqual(roles, tt.wantRoles) {
+				t.Errorf("roles should be %v but: %v", tt.wantRoles, roles)
+			}
+		})
+	}
+
+	client, _ := NewClientWithOptions("dummy-key", "http://localhost", false)
+	if _, err := client.UpsertHostByCustomIdentifier(&CreateHostParam{Name: "vm-1"}, nil); !errors.Is(err, ErrValidation) {
+		t.Errorf("custom identifier should be required but: %v", err)
+	}
+}
@@ -0,0 +1,367 @@
+// Package hostinfo detects the information of the local Linux host as mackerel-agent does,
+// and registers the host to Mackerel keeping its ID in a file.
+package hostinfo
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mackerelio/mackerel-client-go"
+)
+
+// Options configures [Detect].
+// Zero-valued fields fall back to their defaults.
+type Options struct {
+	// Root is the root directory to read /proc, /sys and /etc from. The default is "/".
+	// It is useful to detect the host from a container mounting the root of the host.
+	Root string
+	// AgentName, AgentVersion and AgentRevision are reported in the meta of the host.
+	AgentName     string
+	AgentVersion  string
+	AgentRevision string
+}
+
+// Detect returns the parameter to create the local host.
+// The name of the host is its hostname, and the meta includes CPU, memory, file systems, kernel and block devices.
+// opts may be nil.
+func Detect(opts *Options) (*mackerel.CreateHostParam, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Root == "" {
+		o.Root = "/"
+	}
+	d := &detector{root: o.Root}
+	name, err := d.hostname()
+	if err != nil {
+		return nil, err
+	}
+	cpu, err := d.cpu()
+	if err != nil {
+		return nil, err
+	}
+	memory, err := d.memory()
+	if err != nil {
+		return nil, err
+	}
+	filesystem, err := d.filesystem()
+	if err != nil {
+		return nil, err
+	}
+	kernel, err := d.kernel()
+	if err != nil {
+		return nil, err
+	}
+	blockDevice, err := d.blockDevice()
+	if err != nil {
+		return nil, err
+	}
+	interfaces, err := detectInterfaces()
+	if err != nil {
+		return nil, err
+	}
+	return &mackerel.CreateHostParam{
+		Name: name,
+		Meta: mackerel.HostMeta{
+			AgentName:     o.AgentName,
+			AgentVersion:  o.AgentVersion,
+			AgentRevision: o.AgentRevision,
+			BlockDevice:   blockDevice,
+			CPU:           cpu,
+			Filesystem:    filesystem,
+			Kernel:        kernel,
+			Memory:        memory,
+		},
+		Interfaces: interfaces,
+	}, nil
+}
+
+type detector struct {
+	root string
+}
+
+func (d *detector) path(name string) string {
+	return filepath.Join(d.root, name)
+}
+
+func (d *detector) readString(name string) (string, error) {
+	b, err := os.ReadFile(d.path(name))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func (d *detector) hostname() (string, error) {
+	if name, err := d.readString("proc/sys/kernel/hostname"); err == nil && name != "" {
+		return name, nil
+	}
+	return os.Hostname()
+}
+
+// cpuinfoKeys maps the keys of /proc/cpuinfo to the keys of mackerel-agent.
+var cpuinfoKeys = map[string]string{
+	"vendor_id":   "vendor_id",
+	"cpu family":  "family",
+	"model":       "model",
+	"stepping":    "stepping",
+	"physical id": "physical_id",
+	"core id":     "core_id",
+	"cpu cores":   "cores",
+	"model name":  "model_name",
+	"cpu MHz":     "mhz",
+	"cache size":  "cache_size",
+	"flags":       "flags",
+}
+
+// cpu returns a processor per logical processor in /proc/cpuinfo.
+func (d *detector) cpu() (mackerel.CPU, error) {
+	b, err := os.ReadFile(d.path("proc/cpuinfo"))
+	if err != nil {
+		return nil, err
+	}
+	var cpu mackerel.CPU
+	var p map[string]any
+	for line := range strings.Lines(string(b)) {
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			p = nil
+			continue
+		}
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if k == "processor" {
+			p = make(map[string]any)
+			cpu = append(cpu, p)
+			continue
+		}
+		if key, ok := cpuinfoKeys[k]; ok && p != nil {
+			p[key] = v
+		}
+	}
+	return cpu, nil
+}
+
+// meminfoKeys maps the keys of /proc/meminfo to the keys of mackerel-agent.
+var meminfoKeys = map[string]string{
+	"MemTotal":     "total",
+	"MemFree":      "free",
+	"MemAvailable": "mem_available",
+	"Buffers":      "buffers",
+	"Cached":       "cached",
+	"Active":       "active",
+	"Inactive":     "inactive",
+	"SwapCached":   "swap_cached",
+	"SwapTotal":    "swap_total",
+	"SwapFree":     "swap_free",
+	"Dirty":        "dirty",
+	"Writeback":    "writeback",
+	"AnonPages":    "anon_pages",
+	"Mapped":       "mapped",
+	"Slab":         "slab",
+	"SReclaimable": "slab_reclaimable",
+	"SUnreclaim":   "slab_unreclaim",
+	"PageTables":   "page_tables",
+	"CommitLimit":  "commit_limit",
+	"Committed_AS": "committed_as",
+	"VmallocTotal": "vmalloc_total",
+	"VmallocUsed":  "vmalloc_used",
+	"VmallocChunk": "vmalloc_chunk",
+}
+
+// memory returns the values of /proc/meminfo formatted like "16384kB".
+func (d *detector) memory() (mackerel.Memory, error) {
+	b, err := os.ReadFile(d.path("proc/meminfo"))
+	if err != nil {
+		return nil, err
+	}
+	memory := make(mackerel.Memory)
+	for line := range strings.Lines(string(b)) {
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		if key, ok := meminfoKeys[k]; ok {
+			memory[key] = strings.ReplaceAll(strings.TrimSpace(v), " ", "")
+		}
+	}
+	return memory, nil
+}
+
+// filesystem returns the usages of the devices mounted in /proc/mounts.
+// Pseudo file systems without devices are skipped.
+func (d *detector) filesystem() (mackerel.FileSystem, error) {
+	f, err := os.Open(d.path("proc/mounts"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	filesystem := make(mackerel.FileSystem)
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "/dev/") {
+			continue
+		}
+		device, mount := fields[0], unescapeMount(fields[1])
+		if _, ok := filesystem[device]; ok {
+			continue
+		}
+		size, available, free, err := statfs(d.path(mount))
+		if err != nil || size == 0 {
+			continue
+		}
+		used := size - free
+		filesystem.SetUsage(mackerel.FileSystemUsage{Device: device, Mount: mount, Size: size, Used: used, Available: available})
+		// Rounded up as df does.
+		percent := math.Ceil(float64(used) * 100 / float64(max(used+available, 1)))
+		filesystem[device].(map[string]any)["percent_used"] = strconv.FormatFloat(percent, 'f', 0, 64) + "%"
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return filesystem, nil
+}
+
+// unescapeMount unescapes octal escapes such as "\040" in /proc/mounts.
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// kernel returns the values of uname and the distribution in /etc/os-release.
+func (d *detector) kernel() (mackerel.Kernel, error) {
+	kernel := mackerel.Kernel{"os": "GNU/Linux", "machine": machine()}
+	for key, name := range map[string]string{"name": "ostype", "release": "osrelease", "version": "version"} {
+		v, err := d.readString("proc/sys/kernel/" + name)
+		if err != nil {
+			return nil, err
+		}
+		kernel[key] = v
+	}
+	b, err := os.ReadFile(d.path("etc/os-release"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return kernel, nil
+		}
+		return nil, err
+	}
+	for line := range strings.Lines(string(b)) {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		if s, err := strconv.Unquote(v); err == nil {
+			v = s
+		}
+		switch k {
+		case "NAME":
+			kernel["platform_name"] = v
+		case "VERSION_ID":
+			kernel["platform_version"] = v
+		}
+	}
+	return kernel, nil
+}
+
+// blockDeviceKeys are the files of /sys/block/<device> reported by mackerel-agent, relative to the device.
+var blockDeviceKeys = map[string]string{
+	"size":      "size",
+	"removable": "removable",
+	"model":     "device/model",
+	"rev":       "device/rev",
+	"state":     "device/state",
+	"timeout":   "device/timeout",
+	"vendor":    "device/vendor",
+}
+
+// blockDevice returns the devices in /sys/block except loop and RAM devices.
+// It returns an empty map if /sys/block does not exist as in some containers.
+func (d *detector) blockDevice() (mackerel.BlockDevice, error) {
+	entries, err := os.ReadDir(d.path("sys/block"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	devices := make(mackerel.BlockDevice)
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+			continue
+		}
+		device := make(map[string]any)
+		for key, file := range blockDeviceKeys {
+			if v, err := d.readString(filepath.Join("sys/block", name, file)); err == nil {
+				device[key] = v
+			}
+		}
+		devices[name] = device
+	}
+	return devices, nil
+}
+
+func detectInterfaces() ([]mackerel.Interface, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	var interfaces []mackerel.Interface
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := newInterface(iface.Name, iface.HardwareAddr, addrs); ok {
+			interfaces = append(interfaces, i)
+		}
+	}
+	return interfaces, nil
+}
+
+// newInterface returns the interface of addrs. It returns false if there are no addresses.
+func newInterface(name string, mac net.HardwareAddr, addrs []net.Addr) (mackerel.Interface, bool) {
+	i := mackerel.Interface{Name: name}
+	if len(mac) > 0 {
+		i.MacAddress = mac.String()
+	}
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if ip := ipnet.IP.To4(); ip != nil {
+			i.IPv4Addresses = append(i.IPv4Addresses, ip.String())
+		} else {
+			i.IPv6Addresses = append(i.IPv6Addresses, ipnet.IP.String())
+		}
+	}
+	if len(i.IPv4Addresses) == 0 && len(i.IPv6Addresses) == 0 {
+		return i, false
+	}
+	if len(i.IPv4Addresses) > 0 {
+		i.IPAddress = i.IPv4Addresses[0]
+	}
+	return i, true
+}
@@ -0,0 +1,106 @@
+package hostinfo
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/mackerelio/mackerel-client-go"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"proc/sys/kernel/hostname":  "web01\n",
+		"proc/sys/kernel/ostype":    "Linux\n",
+		"proc/sys/kernel/osrelease": "6.8.0-1-generic\n",
+		"proc/sys/kernel/version":   "#1 SMP PREEMPT_DYNAMIC\n",
+		"proc/cpuinfo": "processor\t: 0\nvendor_id\t: GenuineIntel\nmodel name\t: Intel(R) Xeon(R) CPU\ncpu MHz\t\t: 2400.000\nphysical id\t: 0\ncpu cores\t: 2\n\n" +
+			"processor\t: 1\nvendor_id\t: GenuineIntel\nmodel name\t: Intel(R) Xeon(R) CPU\ncpu MHz\t\t: 2400.000\nphysical id\t: 0\ncpu cores\t: 2\n\n",
+		"proc/meminfo":                "MemTotal:       16384 kB\nMemFree:         8192 kB\nHugePages_Total:       0\n",
+		"proc/mounts":                 "/dev/sda1 / ext4 rw 0 0\nproc /proc proc rw 0 0\n/dev/sda1 /mnt/with\\040space ext4 rw 0 0\n",
+		"etc/os-release":              "NAME=\"Ubuntu\"\nVERSION_ID=\"24.04\"\nID=ubuntu\n",
+		"sys/block/sda/size":          "41943040\n",
+		"sys/block/sda/removable":     "0\n",
+		"sys/block/sda/device/vendor": "ATA\n",
+		"sys/block/loop0/size":        "0\n",
+	})
+	param, err := Detect(&Options{Root: root, AgentName: "custom-agent", AgentVersion: "0.1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if param.Name != "web01" {
+		t.Errorf("name should be the hostname but: %q", param.Name)
+	}
+	meta := param.Meta
+	if meta.AgentName != "custom-agent" || meta.AgentVersion != "0.1.0" {
+		t.Errorf("unexpected agent: %+v", meta)
+	}
+	if info := meta.CPU.Info(); info != (mackerel.CPUInfo{LogicalProcessors: 2, Cores: 2, Model: "Intel(R) Xeon(R) CPU", MHz: 2400}) {
+		t.Errorf("unexpected CPU: %+v", info)
+	}
+	if want := (mackerel.Memory{"total": "16384kB", "free": "8192kB"}); !reflect.DeepEqual(meta.Memory, want) {
+		t.Errorf("unexpected memory: %+v", meta.Memory)
+	}
+	// The sizes of file systems are only available on Linux.
+	if runtime.GOOS == "linux" {
+		usages := meta.Filesystem.Usages()
+		if len(usages) != 1 || usages[0].Device != "/dev/sda1" || usages[0].Mount != "/" || usages[0].Size == 0 {
+			t.Errorf("unexpected file systems: %+v", usages)
+		}
+	}
+	if k := meta.Kernel; k["name"] != "Linux" || k["release"] != "6.8.0-1-generic" || k["platform_name"] != "Ubuntu" || k["platform_version"] != "24.04" {
+		t.Errorf("unexpected kernel: %+v", k)
+	}
+	if want := (mackerel.BlockDevice{"sda": {"size": "41943040", "removable": "0", "vendor": "ATA"}}); !reflect.DeepEqual(meta.BlockDevice, want) {
+		t.Errorf("unexpected block devices: %+v", meta.BlockDevice)
+	}
+
+	if _, err := Detect(&Options{Root: t.TempDir()}); err == nil {
+		t.Error("Detect should fail without /proc")
+	}
+}
+
+func TestNewInterface(t *testing.T) {
+	mac, _ := net.ParseMAC("02:42:ac:11:00:02")
+	addrs := []net.Addr{
+		&net.IPNet{IP: net.ParseIP("fe80::42:acff:fe11:2"), Mask: net.CIDRMask(64, 128)},
+		&net.IPNet{IP: net.ParseIP("172.17.0.2"), Mask: net.CIDRMask(16, 32)},
+	}
+	i, ok := newInterface("eth0", mac, addrs)
+	want := mackerel.Interface{
+		Name:          "eth0",
+		IPAddress:     "172.17.0.2",
+		IPv4Addresses: []string{"172.17.0.2"},
+		IPv6Addresses: []string{"fe80::42:acff:fe11:2"},
+		MacAddress:    "02:42:ac:11:00:02",
+	}
+	if !ok || !reflect.DeepEqual(i, want) {
+		t.Errorf("unexpected interface: %+v", i)
+	}
+	if _, ok := newInterface("eth1", mac, nil); ok {
+		t.Error("interfaces without addresses should be skipped")
+	}
+}
+
+func TestUnescapeMount(t *testing.T) {
+	if s := unescapeMount(`/mnt/with\040space\`); s != `/mnt/with space\` {
+		t.Errorf("unexpected mount: %q", s)
+	}
+}
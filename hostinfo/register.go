@@ -0,0 +1,67 @@
+package hostinfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mackerelio/mackerel-client-go"
+	"github.com/mackerelio/mackerel-client-go/internal/fsutil"
+)
+
+// Register updates the host of the ID in idFile with param, or creates a new host if idFile does not exist or is empty, and returns the ID.
+// The ID of a created host is written to idFile, so that the host is updated instead of duplicated after restarts.
+// A host that was retired or deleted is created again.
+func Register(ctx context.Context, client *mackerel.Client, idFile string, param *mackerel.CreateHostParam) (string, error) {
+	id, err := ReadID(idFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	if id != "" {
+		_, err := client.UpdateHostContext(ctx, id, (*mackerel.UpdateHostParam)(param))
+		if err == nil {
+			return id, nil
+		}
+		if !errors.Is(err, mackerel.ErrNotFound) {
+			return "", err
+		}
+	}
+	id, err = client.CreateHostContext(ctx, param)
+	if err != nil {
+		return "", err
+	}
+	if err := WriteID(idFile, id); err != nil {
+		return "", fmt.Errorf("host %s is created but its ID is not saved: %w", id, err)
+	}
+	return id, nil
+}
+
+// ReadID reads the host ID from path.
+// An empty file, which may be left by a crash, is reported as fs.ErrNotExist like a missing file.
+func ReadID(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	id := strings.TrimSpace(string(b))
+	if id == "" {
+		return "", fmt.Errorf("empty host ID file %s: %w", path, fs.ErrNotExist)
+	}
+	if strings.ContainsAny(id, "/ \t\r\n") {
+		return "", fmt.Errorf("invalid host ID in %s: %q", path, id)
+	}
+	return id, nil
+}
+
+// WriteID replaces path with the host ID atomically, creating its directory if needed.
+func WriteID(path, id string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, []byte(id))
+}
@@ -0,0 +1,71 @@
+package hostinfo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mackerelio/mackerel-client-go"
+	"github.com/mackerelio/mackerel-client-go/mackereltest"
+)
+
+func TestRegister(t *testing.T) {
+	srv := mackereltest.NewServer()
+	defer srv.Close()
+	client, _ := mackerel.NewClientWithOptions("dummy-key", srv.URL, false)
+	idFile := filepath.Join(t.TempDir(), "lib", "id")
+
+	id, err := Register(t.Context(), client, idFile, &mackerel.CreateHostParam{Name: "web01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadID(idFile); err != nil || got != id {
+		t.Errorf("the ID should be saved but: %q, %v", got, err)
+	}
+
+	// The host is updated after restarts.
+	again, err := Register(t.Context(), client, idFile, &mackerel.CreateHostParam{Name: "web01", Memo: "updated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != id {
+		t.Errorf("the host should be updated but %s is created", again)
+	}
+	if hosts, _ := client.FindHosts(&mackerel.FindHostsParam{}); len(hosts) != 1 || hosts[0].Memo != "updated" {
+		t.Errorf("unexpected hosts: %+v", hosts)
+	}
+
+	// A retired host is created again.
+	if err := client.RetireHost(id); err != nil {
+		t.Fatal(err)
+	}
+	created, err := Register(t.Context(), client, idFile, &mackerel.CreateHostParam{Name: "web01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created == id {
+		t.Error("a new host should be created for the retired one")
+	}
+	if got, _ := ReadID(idFile); got != created {
+		t.Errorf("the new ID should be saved but: %q", got)
+	}
+
+	// An empty ID file left by a crash is taken as missing.
+	if err := os.WriteFile(idFile, []byte("\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	recreated, err := Register(t.Context(), client, idFile, &mackerel.CreateHostParam{Name: "web01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ReadID(idFile); got != recreated {
+		t.Errorf("the ID should be saved for the empty ID file but: %q", got)
+	}
+
+	if err := os.WriteFile(idFile, []byte("invalid id\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Register(t.Context(), client, idFile, &mackerel.CreateHostParam{Name: "web01"}); err == nil {
+		t.Error("an invalid ID file should not be overwritten")
+	}
+}
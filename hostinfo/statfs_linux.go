@@ -0,0 +1,30 @@
+package hostinfo
+
+import "syscall"
+
+// statfs returns the size, the available and the free bytes of the file system of path.
+func statfs(path string) (size, available, free uint64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, 0, err
+	}
+	// The blocks are counted in the fragment size, which may differ from the preferred block size Bsize.
+	frsize := uint64(st.Frsize)
+	return st.Blocks * frsize, st.Bavail * frsize, st.Bfree * frsize, nil
+}
+
+// machine returns the hardware name of uname, such as "x86_64".
+func machine() string {
+	var uts syscall.Utsname
+	if err := syscall.Uname(&uts); err != nil {
+		return ""
+	}
+	b := make([]byte, 0, len(uts.Machine))
+	for _, c := range uts.Machine {
+		if c == 0 {
+			break
+		}
+		b = append(b, byte(c))
+	}
+	return string(b)
+}
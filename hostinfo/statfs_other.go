@@ -0,0 +1,16 @@
+//go:build !linux
+
+package hostinfo
+
+import (
+	"errors"
+	"runtime"
+)
+
+func statfs(path string) (size, available, free uint64, err error) {
+	return 0, 0, 0, errors.ErrUnsupported
+}
+
+func machine() string {
+	return runtime.GOARCH
+}
@@ -172,6 +172,7 @@ func (c *Client) FindHost(hostID string) (*Host, error) {
 
 // FindHostContext finds the host.
 func (c *Client) FindHostContext(ctx context.Context, hostID string) (*Host, error) {
+	ctx = withOperationName(ctx, "FindHost")
 	data, err := requestGetContext[struct {
 		Host *Host `json:"host"`
 	}](ctx, c, fmt.Sprintf("/api/v0/hosts/%s", hostID))
@@ -188,6 +189,7 @@ func (c *Client) FindHosts(param *FindHostsParam) ([]*Host, error) {
 
 // FindHostsContext finds hosts.
 func (c *Client) FindHostsContext(ctx context.Context, param *FindHostsParam) ([]*Host, error) {
+	ctx = withOperationName(ctx, "FindHosts")
 	params := url.Values{}
 	if param.Service != "" {
 		params.Set("service", param.Service)
@@ -221,6 +223,7 @@ func (c *Client) FindHostByCustomIdentifier(customIdentifier string, param *Find
 
 // FindHostByCustomIdentifierContext finds a host by the custom identifier.
 func (c *Client) FindHostByCustomIdentifierContext(ctx context.Context, customIdentifier string, param *FindHostByCustomIdentifierParam) (*Host, error) {
+	ctx = withOperationName(ctx, "FindHostByCustomIdentifier")
 	path := "/api/v0/hosts-by-custom-identifier/" + url.PathEscape(customIdentifier)
 	params := url.Values{}
 	if param.CaseInsensitive {
@@ -242,6 +245,7 @@ func (c *Client) CreateHost(param *CreateHostParam) (string, error) {
 
 // CreateHostContext creates a host.
 func (c *Client) CreateHostContext(ctx context.Context, param *CreateHostParam) (string, error) {
+	ctx = withOperationName(ctx, "CreateHost")
 	data, err := requestPostContext[struct {
 		ID string `json:"id"`
 	}](ctx, c, "/api/v0/hosts", param)
@@ -258,6 +262,7 @@ func (c *Client) UpdateHost(hostID string, param *UpdateHostParam) (string, erro
 
 // UpdateHostContext updates a host.
 func (c *Client) UpdateHostContext(ctx context.Context, hostID string, param *UpdateHostParam) (string, error) {
+	ctx = withOperationName(ctx, "UpdateHost")
 	path := fmt.Sprintf("/api/v0/hosts/%s", hostID)
 	data, err := requestPutContext[struct {
 		ID string `json:"id"`
@@ -275,6 +280,7 @@ func (c *Client) UpdateHostStatus(hostID string, status string) error {
 
 // UpdateHostStatusContext updates a host status.
 func (c *Client) UpdateHostStatusContext(ctx context.Context, hostID string, status string) error {
+	ctx = withOperationName(ctx, "UpdateHostStatus")
 	path := fmt.Sprintf("/api/v0/hosts/%s/status", hostID)
 	_, err := requestPostContext[any](ctx, c, path, map[string]string{"status": status})
 	return err
@@ -287,6 +293,7 @@ func (c *Client) BulkUpdateHostStatuses(ids []string, status string) error {
 
 // BulkUpdateHostStatusesContext updates status of the hosts.
 func (c *Client) BulkUpdateHostStatusesContext(ctx context.Context, ids []string, status string) error {
+	ctx = withOperationName(ctx, "BulkUpdateHostStatuses")
 	_, err := requestPostContext[any](ctx, c, "/api/v0/hosts/bulk-update-statuses",
 		map[string]any{"ids": ids, "status": status})
 	return err
@@ -299,6 +306,7 @@ func (c *Client) UpdateHostRoleFullnames(hostID string, roleFullnames []string)
 
 // UpdateHostRoleFullnamesContext updates host roles.
 func (c *Client) UpdateHostRoleFullnamesContext(ctx context.Context, hostID string, roleFullnames []string) error {
+	ctx = withOperationName(ctx, "UpdateHostRoleFullnames")
 	path := fmt.Sprintf("/api/v0/hosts/%s/role-fullnames", hostID)
 	_, err := requestPutContext[any](ctx, c, path, map[string][]string{"roleFullnames": roleFullnames})
 	return err
@@ -311,6 +319,7 @@ func (c *Client) RetireHost(hostID string) error {
 
 // RetireHostContext retires the host.
 func (c *Client) RetireHostContext(ctx context.Context, hostID string) error {
+	ctx = withOperationName(ctx, "RetireHost")
 	path := fmt.Sprintf("/api/v0/hosts/%s/retire", hostID)
 	_, err := requestPostContext[any](ctx, c, path, nil)
 	return err
@@ -323,6 +332,7 @@ func (c *Client) BulkRetireHosts(ids []string) error {
 
 // BulkRetireHostsContext retires the hosts.
 func (c *Client) BulkRetireHostsContext(ctx context.Context, ids []string) error {
+	ctx = withOperationName(ctx, "BulkRetireHosts")
 	_, err := requestPostContext[any](ctx, c, "/api/v0/hosts/bulk-retire", map[string][]string{"ids": ids})
 	return err
 }
@@ -334,6 +344,7 @@ func (c *Client) ListHostMetricNames(hostID string) ([]string, error) {
 
 // ListHostMetricNamesContext lists metric names of a host.
 func (c *Client) ListHostMetricNamesContext(ctx context.Context, hostID string) ([]string, error) {
+	ctx = withOperationName(ctx, "ListHostMetricNames")
 	data, err := requestGetContext[struct {
 		Names []string `json:"names"`
 	}](ctx, c, fmt.Sprintf("/api/v0/hosts/%s/metric-names", hostID))
@@ -350,6 +361,7 @@ func (c *Client) ListMonitoredStatues(hostID string) ([]MonitoredStatus, error)
 
 // ListMonitoredStatuesContext lists monitored statues of a host.
 func (c *Client) ListMonitoredStatuesContext(ctx context.Context, hostID string) ([]MonitoredStatus, error) {
+	ctx = withOperationName(ctx, "ListMonitoredStatues")
 	data, err := requestGetContext[struct {
 		MonitoredStatuses []MonitoredStatus `json:"monitoredStatuses"`
 	}](ctx, c, fmt.Sprintf("/api/v0/hosts/%s/monitored-statuses", hostID))
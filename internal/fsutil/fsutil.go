@@ -0,0 +1,35 @@
+// Package fsutil provides file operations shared by the packages of this module.
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic replaces path with b. It writes to a temporary file in the same directory
+// and syncs it before renaming, so that path is never left incomplete even after a crash.
+func WriteFileAtomic(path string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()           // nolint
+		os.Remove(f.Name()) // nolint
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()           // nolint
+		os.Remove(f.Name()) // nolint
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name()) // nolint
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name()) // nolint
+		return err
+	}
+	return nil
+}
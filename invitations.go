@@ -16,6 +16,7 @@ func (c *Client) FindInvitations() ([]*Invitation, error) {
 
 // FindInvitationsContext finds invitations.
 func (c *Client) FindInvitationsContext(ctx context.Context) ([]*Invitation, error) {
+	ctx = withOperationName(ctx, "FindInvitations")
 	data, err := requestGetContext[struct {
 		Invitations []*Invitation `json:"invitations"`
 	}](ctx, c, "/api/v0/invitations")
@@ -32,5 +33,6 @@ func (c *Client) CreateInvitation(param *Invitation) (*Invitation, error) {
 
 // CreateInvitationContext creates a invitation.
 func (c *Client) CreateInvitationContext(ctx context.Context, param *Invitation) (*Invitation, error) {
+	ctx = withOperationName(ctx, "CreateInvitation")
 	return requestPostContext[Invitation](ctx, c, "/api/v0/invitations", param)
 }
@@ -0,0 +1,212 @@
+package mackerel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"time"
+)
+
+const redactedAPIKey = "[REDACTED]"
+
+// slogger returns the logger of structured events, or nil if no logger is set.
+// Logger and PrioritizedLogger are bridged when SlogLogger is not set and Verbose is enabled,
+// so that they keep receiving nothing but the dumps otherwise.
+func (c *Client) slogger() *slog.Logger {
+	if c.SlogLogger != nil {
+		return c.SlogLogger
+	}
+	if c.Verbose && (c.PrioritizedLogger != nil || c.Logger != nil) {
+		return slog.New(&bridgeHandler{client: c})
+	}
+	return nil
+}
+
+// logRetry logs that the n-th attempt of req failed and it is retried after wait.
+func (c *Client) logRetry(op *Operation, req *http.Request, n int, resp *http.Response, err error, wait time.Duration) {
+	l := c.slogger()
+	if l == nil {
+		return
+	}
+	attrs := append(operationAttrs(op, n), slog.Duration("wait", wait))
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	l.LogAttrs(req.Context(), slog.LevelWarn, "retrying Mackerel API request", attrs...)
+}
+
+// logResult logs the result of the last attempt of req. Successful requests are logged at debug level,
+// client errors at warn level and the others at error level.
+// When resp is not nil, its body may be replaced to be logged.
+func (c *Client) logResult(op *Operation, req *http.Request, n int, elapsed time.Duration, resp *http.Response, err error) {
+	l := c.slogger()
+	if l == nil {
+		return
+	}
+	ctx := req.Context()
+	level := slog.LevelDebug
+	attrs := append(operationAttrs(op, n), slog.Duration("duration", elapsed))
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr):
+		level = slog.LevelError
+		if apiErr.StatusCode < 500 {
+			level = slog.LevelWarn
+		}
+		attrs = append(attrs, slog.Int("status", apiErr.StatusCode), slog.String("error", apiErr.Message))
+		if apiErr.RequestID != "" {
+			attrs = append(attrs, slog.String("request_id", apiErr.RequestID))
+		}
+	case err != nil:
+		level = slog.LevelError
+		attrs = append(attrs, slog.String("error", err.Error()))
+	default:
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	if c.LogBodyLimit != 0 && l.Enabled(ctx, slog.LevelDebug) {
+		if body, ok := requestBody(req); ok {
+			attrs = append(attrs, slog.String("request_body", truncateBody(body, c.LogBodyLimit)))
+		}
+		switch {
+		case apiErr != nil:
+			attrs = append(attrs, slog.String("response_body", truncateBody(apiErr.Body, c.LogBodyLimit)))
+		case resp != nil:
+			body, rerr := io.ReadAll(resp.Body)
+			resp.Body.Close() // nolint
+			resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{rerr}))
+			attrs = append(attrs, slog.String("response_body", truncateBody(body, c.LogBodyLimit)))
+		}
+	}
+	l.LogAttrs(ctx, level, "Mackerel API request", attrs...)
+}
+
+func operationAttrs(op *Operation, n int) []slog.Attr {
+	return []slog.Attr{
+		slog.String("operation", op.Name),
+		slog.String("method", op.Method),
+		slog.String("path", op.Path),
+		slog.Int("attempt", n),
+	}
+}
+
+// requestBody returns a copy of the body of req if it can be read again.
+func requestBody(req *http.Request) ([]byte, bool) {
+	if req.GetBody == nil || req.ContentLength == 0 {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	defer body.Close() // nolint
+	b, err := io.ReadAll(body)
+	return b, err == nil
+}
+
+// truncateBody returns body as a string truncated to limit bytes. Negative limit means unlimited.
+func truncateBody(body []byte, limit int) string {
+	body = bytes.TrimSpace(body)
+	if limit < 0 || len(body) <= limit {
+		return string(body)
+	}
+	return fmt.Sprintf("%s...(%d bytes truncated)", body[:limit], len(body)-limit)
+}
+
+// errReader returns err after the body is read, or io.EOF if err is nil.
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
+
+// dumpRequest dumps req with X-Api-Key redacted. The headers are redacted on a clone
+// because req may be shared with the other goroutines.
+func dumpRequest(req *http.Request) ([]byte, error) {
+	r := req.Clone(req.Context())
+	if r.Header.Get("X-Api-Key") != "" {
+		r.Header.Set("X-Api-Key", redactedAPIKey)
+	}
+	if req.GetBody == nil {
+		// DumpRequest consumes the body shared with req and replaces it with a copy.
+		defer func() { req.Body = r.Body }()
+	} else if body, err := req.GetBody(); err == nil {
+		r.Body = body
+	}
+	return httputil.DumpRequest(r, true)
+}
+
+// bridgeHandler is a [slog.Handler] that sends records to Logger and PrioritizedLogger of client.
+type bridgeHandler struct {
+	client *Client
+	attrs  string
+	group  string
+}
+
+// Enabled implements [slog.Handler]. Logger only receives records of info level and above,
+// whereas PrioritizedLogger receives all the records to filter by itself.
+func (h *bridgeHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.client.PrioritizedLogger != nil || level >= slog.LevelInfo
+}
+
+// Handle implements [slog.Handler].
+func (h *bridgeHandler) Handle(_ context.Context, r slog.Record) error {
+	var sb strings.Builder
+	sb.WriteString(r.Message)
+	sb.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		sb.WriteString(formatAttr(h.group, a))
+		return true
+	})
+	msg := sb.String()
+	if l := h.client.PrioritizedLogger; l != nil {
+		switch {
+		case r.Level >= slog.LevelError:
+			l.Errorf("%s", msg)
+		case r.Level >= slog.LevelWarn:
+			l.Warningf("%s", msg)
+		case r.Level >= slog.LevelInfo:
+			l.Infof("%s", msg)
+		default:
+			l.Debugf("%s", msg)
+		}
+	}
+	if l := h.client.Logger; l != nil && r.Level >= slog.LevelInfo {
+		l.Printf("%s %s", r.Level, msg)
+	}
+	return nil
+}
+
+// WithAttrs implements [slog.Handler].
+func (h *bridgeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	for _, a := range attrs {
+		h2.attrs += formatAttr(h.group, a)
+	}
+	return &h2
+}
+
+// WithGroup implements [slog.Handler].
+func (h *bridgeHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.group += name + "."
+	return &h2
+}
+
+func formatAttr(group string, a slog.Attr) string {
+	return fmt.Sprintf(" %s%s=%q", group, a.Key, a.Value.String())
+}
@@ -0,0 +1,158 @@
+package mackerel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeLogRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var r map[string]any
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestSlogLogger(t *testing.T) {
+	var failures int
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if failures > 0 {
+			failures--
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if req.URL.Path == "/api/v0/hosts/unknown" {
+			res.Header().Set("X-Request-Id", "req-1")
+			res.WriteHeader(http.StatusNotFound)
+			res.Write([]byte(`{"error":{"message":"Host Not Found."}}`)) // nolint
+			return
+		}
+		res.Write([]byte(`{"id":"9rxGOHfVF8F","padding":"` + strings.Repeat("x", 100) + `"}`)) // nolint
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	client, err := New("secret-api-key",
+		WithBaseURL(ts.URL),
+		WithVerbose(true),
+		WithSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		WithLogBodyLimit(20),
+		WithRetryPolicy(&RetryPolicy{BaseBackoff: time.Millisecond, RetryNonIdempotent: true}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failures = 1
+	if _, err := client.CreateHostContext(t.Context(), &CreateHostParam{Name: "web01"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.FindHostContext(t.Context(), "unknown"); err == nil {
+		t.Fatal("FindHost of unknown host should fail")
+	}
+
+	output := buf.String()
+	if strings.Contains(output, "secret-api-key") {
+		t.Error("API key should be redacted but: ", output)
+	}
+	var events []map[string]any
+	for _, r := range decodeLogRecords(t, &buf) {
+		if _, ok := r["operation"]; ok {
+			events = append(events, r)
+		}
+	}
+	if len(events) != 3 {
+		t.Fatalf("3 events should be logged but: %v", events)
+	}
+	if e := events[0]; e["level"] != "WARN" || e["operation"] != "CreateHost" || e["attempt"] != 1.0 || e["status"] != 503.0 {
+		t.Error("retry should be logged at warn level but: ", e)
+	}
+	e := events[1]
+	if e["level"] != "DEBUG" || e["attempt"] != 2.0 || e["status"] != 200.0 || e["path"] != "/api/v0/hosts" {
+		t.Error("success should be logged at debug level but: ", e)
+	}
+	if body, _ := e["response_body"].(string); !strings.HasPrefix(body, `{"id":"9rxGOHfVF8F",`) || !strings.HasSuffix(body, "bytes truncated)") {
+		t.Error("response body should be truncated but: ", body)
+	}
+	if body, _ := e["request_body"].(string); !strings.HasPrefix(body, `{"name":"web01"`) {
+		t.Error("request body should be logged but: ", body)
+	}
+	if e := events[2]; e["level"] != "WARN" || e["status"] != 404.0 || e["error"] != "Host Not Found." || e["request_id"] != "req-1" {
+		t.Error("client error should be logged at warn level but: ", e)
+	}
+}
+
+type testPrioritizedLogger struct {
+	lines []string
+}
+
+func (l *testPrioritizedLogger) logf(level, format string, v ...any) {
+	l.lines = append(l.lines, level+" "+fmt.Sprintf(format, v...))
+}
+func (l *testPrioritizedLogger) Tracef(format string, v ...any)   { l.logf("TRACE", format, v...) }
+func (l *testPrioritizedLogger) Debugf(format string, v ...any)   { l.logf("DEBUG", format, v...) }
+func (l *testPrioritizedLogger) Infof(format string, v ...any)    { l.logf("INFO", format, v...) }
+func (l *testPrioritizedLogger) Warningf(format string, v ...any) { l.logf("WARNING", format, v...) }
+func (l *testPrioritizedLogger) Errorf(format string, v ...any)   { l.logf("ERROR", format, v...) }
+
+func TestSlogLogger_Bridge(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	logger := &testPrioritizedLogger{}
+	client, _ := New("dummy-key", WithBaseURL(ts.URL), WithPrioritizedLogger(logger))
+	if _, err := client.GetOrgContext(t.Context()); err == nil {
+		t.Fatal("GetOrg should fail")
+	}
+	if len(logger.lines) != 0 {
+		t.Error("events should not be bridged unless verbose but: ", logger.lines)
+	}
+
+	client.Verbose = true
+	if _, err := client.GetOrgContext(t.Context()); err == nil {
+		t.Fatal("GetOrg should fail")
+	}
+	var errs []string
+	for _, line := range logger.lines {
+		if strings.HasPrefix(line, "ERROR ") {
+			errs = append(errs, line)
+		}
+	}
+	if len(errs) != 1 || !strings.HasPrefix(errs[0], `ERROR Mackerel API request operation="GetOrg"`) ||
+		!strings.Contains(errs[0], `status="500"`) {
+		t.Error("error should be bridged to PrioritizedLogger but: ", logger.lines)
+	}
+}
+
+func TestDumpRequest(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost/api/v0/hosts", io.NopCloser(strings.NewReader(`{"name":"web01"}`)))
+	req.Header.Set("X-Api-Key", "secret-api-key")
+	dump, err := dumpRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(dump); strings.Contains(s, "secret-api-key") || !strings.Contains(s, redactedAPIKey) || !strings.HasSuffix(s, `{"name":"web01"}`) {
+		t.Error("API key should be redacted but: ", s)
+	}
+	if key := req.Header.Get("X-Api-Key"); key != "secret-api-key" {
+		t.Errorf("the header of the request should be kept but: %q", key)
+	}
+	if body, _ := io.ReadAll(req.Body); string(body) != `{"name":"web01"}` {
+		t.Errorf("the body of the request should be kept but: %q", body)
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"slices"
 	"time"
 )
 
@@ -37,10 +39,33 @@ type Client struct {
 	AdditionalHeaders http.Header
 	HTTPClient        *http.Client
 
+	// RetryPolicy controls retries of failed requests. When nil, requests are not retried.
+	RetryPolicy *RetryPolicy
+
+	// RateLimiter limits the rate of requests. When nil, requests are not limited.
+	RateLimiter RateLimiter
+
+	// Middlewares wrap sending requests, the first one being the outermost.
+	Middlewares []Middleware
+
+	// DryRun, if set, records mutating requests into its plan instead of sending them.
+	DryRun *DryRun
+
 	// Client will send logging events to both Logger and PrioritizedLogger.
 	// When neither Logger or PrioritizedLogger is set, the log package's standard logger will be used.
 	Logger            *log.Logger
 	PrioritizedLogger PrioritizedLogger
+
+	// SlogLogger receives structured events of requests: results at debug level,
+	// retries and client errors at warn level, and the other errors at error level.
+	// The API key is never logged. When nil and Verbose is enabled, the events are sent to Logger and PrioritizedLogger,
+	// where Logger only receives those of info level and above.
+	// Verbose dumps are also sent to SlogLogger at debug level when neither Logger or PrioritizedLogger is set.
+	SlogLogger *slog.Logger
+
+	// LogBodyLimit is the maximum number of bytes of request and response bodies
+	// included in the debug events. Zero disables logging bodies, and negative means unlimited.
+	LogBodyLimit int
 }
 
 // NewClient returns new mackerel.Client
@@ -96,22 +121,90 @@ func (c *Client) tracef(format string, v ...any) {
 		c.Logger.Printf(format, v...)
 	}
 	if c.PrioritizedLogger == nil && c.Logger == nil {
+		if c.SlogLogger != nil {
+			c.SlogLogger.Debug(fmt.Sprintf(format, v...))
+			return
+		}
 		log.Printf(format, v...)
 	}
 }
 
 // Request request to mackerel and receive response
 func (c *Client) Request(req *http.Request) (resp *http.Response, err error) {
+	return c.send(newOperation("Request", req.Method, req.URL.Path, req.URL.Query(), nil), req)
+}
+
+// send sends req through Middlewares.
+func (c *Client) send(op *Operation, req *http.Request) (*http.Response, error) {
 	req = c.buildReq(req)
+	h := Handler(c.roundTrip)
+	if c.DryRun != nil {
+		h = c.DryRun.wrap(h)
+	}
+	for _, m := range slices.Backward(c.Middlewares) {
+		h = m(h)
+	}
+	return h(op, req)
+}
 
+// roundTrip sends req with retries and rate limiting.
+func (c *Client) roundTrip(op *Operation, req *http.Request) (resp *http.Response, err error) {
+	start := time.Now()
+	for n := 1; ; n++ {
+		op.Attempts = n
+		r := req
+		if n > 1 {
+			if r, err = rewindRequest(req); err != nil {
+				return nil, err
+			}
+		}
+		if c.RateLimiter != nil {
+			if err := c.RateLimiter.Wait(r); err != nil {
+				return nil, err
+			}
+		}
+		resp, err = c.do(r)
+		if c.RateLimiter != nil && resp != nil {
+			c.RateLimiter.Observe(resp)
+		}
+		wait, ok := c.RetryPolicy.retryDelay(req, n, resp, err)
+		if !ok {
+			break
+		}
+		c.logRetry(op, req, n, resp, err, wait)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body) // nolint
+			resp.Body.Close()              // nolint
+		}
+		if c.Verbose {
+			c.tracef("retrying %s %s in %s (attempt %d)", req.Method, req.URL.Path, wait, n+1)
+		}
+		if err := sleepContext(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+	elapsed := time.Since(start)
+	if err == nil && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+		err = newAPIError(resp)
+		resp.Body.Close() // nolint
+		resp = nil
+	}
+	c.logResult(op, req, op.Attempts, elapsed, resp, err)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) do(req *http.Request) (*http.Response, error) {
 	if c.Verbose {
-		dump, err := httputil.DumpRequest(req, true)
+		dump, err := dumpRequest(req)
 		if err == nil {
 			c.tracef("%s", dump)
 		}
 	}
 
-	resp, err = c.HTTPClient.Do(req)
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -121,14 +214,6 @@ func (c *Client) Request(req *http.Request) (resp *http.Response, err error) {
 			c.tracef("%s", dump)
 		}
 	}
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		message, err := extractErrorMessage(resp.Body)
-		defer resp.Body.Close() // nolint
-		if err != nil {
-			return nil, &APIError{StatusCode: resp.StatusCode, Message: resp.Status}
-		}
-		return nil, &APIError{StatusCode: resp.StatusCode, Message: message}
-	}
 	return resp, nil
 }
 
@@ -162,7 +247,7 @@ func requestJSON[T any](ctx context.Context, client *Client, method, path string
 	if err != nil {
 		return nil, err
 	}
-	data, _, err := requestInternal[T](ctx, client, method, path, nil, &body)
+	data, _, err := requestOperation[T](ctx, client, newOperation(operationName(ctx), method, path, nil, payload), &body)
 	return data, err
 }
 
@@ -172,7 +257,12 @@ func requestNoBody[T any](ctx context.Context, client *Client, method, path stri
 }
 
 func requestInternal[T any](ctx context.Context, client *Client, method, path string, params url.Values, body io.Reader) (*T, http.Header, error) {
-	u := client.urlFor(path, params)
+	return requestOperation[T](ctx, client, newOperation(operationName(ctx), method, path, params, nil), body)
+}
+
+func requestOperation[T any](ctx context.Context, client *Client, op *Operation, body io.Reader) (*T, http.Header, error) {
+	method := op.Method
+	u := client.urlFor(op.Path, op.Query)
 	var user string
 	if ui := u.User; ui != nil {
 		user = ui.String() + "@"
@@ -186,7 +276,7 @@ func requestInternal[T any](ctx context.Context, client *Client, method, path st
 		req.Header.Add("Content-Type", "application/json")
 	}
 
-	resp, err := client.Request(req)
+	resp, err := client.send(op, req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -215,7 +305,7 @@ func (c *Client) compatRequestJSON(ctx context.Context, method string, path stri
 		return nil, err
 	}
 	req.Header.Add("Content-Type", "application/json")
-	return c.Request(req)
+	return c.send(newOperation(operationName(ctx), method, path, nil, payload), req)
 }
 
 // ToPtr returns a pointer to the given value of any type.
@@ -225,20 +315,22 @@ func ToPtr[T any](v T) *T {
 
 // Deprecated: use other prefered method.
 func (c *Client) PostJSON(path string, payload any) (*http.Response, error) {
-	return c.compatRequestJSON(context.Background(), http.MethodPost, path, payload)
+	return c.PostJSONContext(context.Background(), path, payload)
 }
 
 // PostJSONContext shortcut method for posting json
 func (c *Client) PostJSONContext(ctx context.Context, path string, payload any) (*http.Response, error) {
+	ctx = withOperationName(ctx, "PostJSON")
 	return c.compatRequestJSON(ctx, http.MethodPost, path, payload)
 }
 
 // Deprecated: use other prefered method.
 func (c *Client) PutJSON(path string, payload any) (*http.Response, error) {
-	return c.compatRequestJSON(context.Background(), http.MethodPut, path, payload)
+	return c.PutJSONContext(context.Background(), path, payload)
 }
 
 // PutJSONContext shortcut method for putting json
 func (c *Client) PutJSONContext(ctx context.Context, path string, payload any) (*http.Response, error) {
+	ctx = withOperationName(ctx, "PutJSON")
 	return c.compatRequestJSON(ctx, http.MethodPut, path, payload)
 }
@@ -0,0 +1,173 @@
+package mackereltest
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/mackerelio/mackerel-client-go"
+)
+
+const defaultAlertsLimit = 100
+
+func (s *Server) routeAlerts() {
+	s.handle("GET /api/v0/alerts", s.findAlerts)
+	s.handle("GET /api/v0/alerts/{id}", s.getAlert)
+	s.handle("PUT /api/v0/alerts/{id}", s.updateAlert)
+	s.handle("POST /api/v0/alerts/{id}/close", s.closeAlert)
+	s.handle("GET /api/v0/alerts/{id}/logs", s.findAlertLogs)
+}
+
+// AddAlert registers a as is and returns its ID, since alerts cannot be created through the API.
+// An ID is assigned if a.ID is empty, and OpenedAt defaults to the current time.
+func (s *Server) AddAlert(a *mackerel.Alert) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	alert := *a
+	if alert.ID == "" {
+		alert.ID = newID()
+	}
+	if alert.OpenedAt == 0 {
+		alert.OpenedAt = s.unixNow()
+	}
+	if _, ok := s.alerts[alert.ID]; !ok {
+		s.alertIDs = append(s.alertIDs, alert.ID)
+	}
+	s.alerts[alert.ID] = &alert
+	s.addAlertLog(&alert, "monitoring", alert.OpenedAt)
+	return alert.ID
+}
+
+func (s *Server) addAlertLog(a *mackerel.Alert, trigger string, at int64) {
+	log := &mackerel.AlertLog{
+		ID:        newID(),
+		CreatedAt: at,
+		Status:    a.Status,
+		Trigger:   trigger,
+	}
+	if a.MonitorID != "" {
+		log.MonitorID = &a.MonitorID
+	}
+	s.alertLogs[a.ID] = append(s.alertLogs[a.ID], log)
+}
+
+// page returns up to limit items of ids that follow nextID in reverse order,
+// and the ID to fetch the next page.
+func page(ids []string, nextID string, limit int) ([]string, string) {
+	ids = slices.Clone(ids)
+	slices.Reverse(ids)
+	if nextID != "" {
+		i := slices.Index(ids, nextID)
+		if i < 0 {
+			return nil, ""
+		}
+		ids = ids[i:]
+	}
+	if len(ids) <= limit {
+		return ids, ""
+	}
+	return ids[:limit], ids[limit]
+}
+
+func parseLimit(w http.ResponseWriter, r *http.Request, def int) (int, bool) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid limit")
+		return 0, false
+	}
+	return n, true
+}
+
+func (s *Server) findAlerts(w http.ResponseWriter, r *http.Request) {
+	limit, ok := parseLimit(w, r, defaultAlertsLimit)
+	if !ok {
+		return
+	}
+	ids := s.alertIDs
+	if r.URL.Query().Get("withClosed") != "true" {
+		ids = slices.DeleteFunc(slices.Clone(ids), func(id string) bool { return s.alerts[id].Status == "OK" })
+	}
+	ids, nextID := page(ids, r.URL.Query().Get("nextId"), limit)
+	alerts := make([]*mackerel.Alert, len(ids))
+	for i, id := range ids {
+		alerts[i] = s.alerts[id]
+	}
+	writeJSON(w, http.StatusOK, &mackerel.AlertsResp{Alerts: alerts, NextID: nextID})
+}
+
+func (s *Server) getAlert(w http.ResponseWriter, r *http.Request) {
+	a, ok := s.alerts[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w, "Alert")
+		return
+	}
+	writeJSON(w, http.StatusOK, a)
+}
+
+func (s *Server) updateAlert(w http.ResponseWriter, r *http.Request) {
+	a, ok := s.alerts[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w, "Alert")
+		return
+	}
+	var param mackerel.UpdateAlertParam
+	if !decodeBody(w, r, &param) {
+		return
+	}
+	alert := *a
+	alert.Memo = param.Memo
+	s.alerts[alert.ID] = &alert
+	writeJSON(w, http.StatusOK, &mackerel.UpdateAlertResponse{Memo: alert.Memo})
+}
+
+func (s *Server) closeAlert(w http.ResponseWriter, r *http.Request) {
+	a, ok := s.alerts[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w, "Alert")
+		return
+	}
+	var param struct {
+		Reason string `json:"reason"`
+	}
+	if !decodeBody(w, r, &param) {
+		return
+	}
+	if a.Status == "OK" {
+		writeError(w, http.StatusBadRequest, "Alert is already closed.")
+		return
+	}
+	alert := *a
+	alert.Status = "OK"
+	alert.Reason = param.Reason
+	alert.ClosedAt = s.unixNow()
+	s.alerts[alert.ID] = &alert
+	s.addAlertLog(&alert, "manual", alert.ClosedAt)
+	writeJSON(w, http.StatusOK, &alert)
+}
+
+func (s *Server) findAlertLogs(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.alerts[id]; !ok {
+		writeNotFound(w, "Alert")
+		return
+	}
+	limit, ok := parseLimit(w, r, defaultAlertsLimit)
+	if !ok {
+		return
+	}
+	logs := s.alertLogs[id]
+	ids := make([]string, len(logs))
+	for i, l := range logs {
+		ids[i] = l.ID
+	}
+	ids, nextID := page(ids, r.URL.Query().Get("nextId"), limit)
+	res := &mackerel.FindAlertLogsResp{AlertLogs: make([]*mackerel.AlertLog, len(ids)), NextID: nextID}
+	for i, lid := range ids {
+		res.AlertLogs[i] = logs[slices.IndexFunc(logs, func(l *mackerel.AlertLog) bool { return l.ID == lid })]
+	}
+	writeJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,318 @@
+package mackereltest
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mackerelio/mackerel-client-go"
+)
+
+func (s *Server) routeHosts() {
+	s.handle("GET /api/v0/hosts", s.findHosts)
+	s.handle("POST /api/v0/hosts", s.createHost)
+	s.handle("GET /api/v0/hosts/{id}", s.findHost)
+	s.handle("PUT /api/v0/hosts/{id}", s.updateHost)
+	s.handle("POST /api/v0/hosts/{id}/status", s.updateHostStatus)
+	s.handle("PUT /api/v0/hosts/{id}/role-fullnames", s.updateHostRoleFullnames)
+	s.handle("POST /api/v0/hosts/{id}/retire", s.retireHost)
+	s.handle("POST /api/v0/hosts/bulk-retire", s.bulkRetireHosts)
+	s.handle("POST /api/v0/hosts/bulk-update-statuses", s.bulkUpdateHostStatuses)
+	s.handle("GET /api/v0/hosts/{id}/metric-names", s.listHostMetricNames)
+	s.handle("GET /api/v0/hosts-by-custom-identifier/{customIdentifier}", s.findHostByCustomIdentifier)
+}
+
+// AddHost registers h as is and returns its ID. An ID is assigned if h.ID is empty.
+// It is useful to prepare hosts in a state that cannot be made through the API.
+func (s *Server) AddHost(h *mackerel.Host) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	host := *h
+	if host.ID == "" {
+		host.ID = newID()
+	}
+	s.putHost(&host)
+	return host.ID
+}
+
+func (s *Server) putHost(h *mackerel.Host) {
+	if _, ok := s.hosts[h.ID]; !ok {
+		s.hostIDs = append(s.hostIDs, h.ID)
+	}
+	s.hosts[h.ID] = h
+	for service, roles := range h.Roles {
+		for _, role := range roles {
+			s.ensureRole(service, role)
+		}
+	}
+}
+
+func (s *Server) workingHost(w http.ResponseWriter, id string) (*mackerel.Host, bool) {
+	h, ok := s.hosts[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Host Not Found.")
+		return nil, false
+	}
+	if h.IsRetired {
+		writeError(w, http.StatusNotFound, "Host Not Found.")
+		return nil, false
+	}
+	return h, true
+}
+
+func parseRoleFullnames(fullnames []string) (mackerel.Roles, bool) {
+	roles := make(mackerel.Roles)
+	for _, fullname := range fullnames {
+		service, role, ok := strings.Cut(fullname, ":")
+		service, role = strings.TrimSpace(service), strings.TrimSpace(role)
+		if !ok || service == "" || role == "" {
+			return nil, false
+		}
+		if !slices.Contains(roles[service], role) {
+			roles[service] = append(roles[service], role)
+		}
+	}
+	return roles, true
+}
+
+func (s *Server) findHosts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	statuses := q["status"]
+	if len(statuses) == 0 {
+		statuses = []string{mackerel.HostStatusWorking, mackerel.HostStatusStandby}
+	}
+	service := q.Get("service")
+	hosts := []*mackerel.Host{}
+	for _, id := range s.hostIDs {
+		h := s.hosts[id]
+		if h.IsRetired || !slices.Contains(statuses, h.Status) {
+			continue
+		}
+		if service != "" {
+			roles, ok := h.Roles[service]
+			if !ok {
+				continue
+			}
+			if wants := q["role"]; len(wants) > 0 && !slices.ContainsFunc(wants, func(r string) bool { return slices.Contains(roles, r) }) {
+				continue
+			}
+		}
+		if name := q.Get("name"); name != "" && h.Name != name {
+			continue
+		}
+		if ci := q.Get("customIdentifier"); ci != "" && h.CustomIdentifier != ci {
+			continue
+		}
+		hosts = append(hosts, h)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"hosts": hosts})
+}
+
+func (s *Server) createHost(w http.ResponseWriter, r *http.Request) {
+	var param mackerel.CreateHostParam
+	if !decodeBody(w, r, &param) {
+		return
+	}
+	if param.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	roles, ok := parseRoleFullnames(param.RoleFullnames)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid roleFullnames")
+		return
+	}
+	if param.CustomIdentifier != "" {
+		for _, h := range s.hosts {
+			if !h.IsRetired && h.CustomIdentifier == param.CustomIdentifier {
+				writeError(w, http.StatusConflict, "customIdentifier already exists")
+				return
+			}
+		}
+	}
+	h := &mackerel.Host{
+		ID:               newID(),
+		Name:             param.Name,
+		DisplayName:      param.DisplayName,
+		CustomIdentifier: param.CustomIdentifier,
+		Size:             "standard",
+		Status:           mackerel.HostStatusWorking,
+		Memo:             param.Memo,
+		Roles:            roles,
+		CreatedAt:        int32(s.unixNow()),
+		Meta:             param.Meta,
+		Interfaces:       param.Interfaces,
+	}
+	s.putHost(h)
+	writeJSON(w, http.StatusOK, map[string]string{"id": h.ID})
+}
+
+func (s *Server) findHost(w http.ResponseWriter, r *http.Request) {
+	h, ok := s.hosts[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Host Not Found.")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"host": h})
+}
+
+func (s *Server) updateHost(w http.ResponseWriter, r *http.Request) {
+	h, ok := s.workingHost(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	var param mackerel.UpdateHostParam
+	if !decodeBody(w, r, &param) {
+		return
+	}
+	roles, ok := parseRoleFullnames(param.RoleFullnames)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid roleFullnames")
+		return
+	}
+	host := *h
+	host.Name = param.Name
+	host.DisplayName = param.DisplayName
+	host.Memo = param.Memo
+	host.Meta = param.Meta
+	host.Interfaces = param.Interfaces
+	if param.RoleFullnames != nil {
+		host.Roles = roles
+	}
+	if param.CustomIdentifier != "" {
+		host.CustomIdentifier = param.CustomIdentifier
+	}
+	s.putHost(&host)
+	writeJSON(w, http.StatusOK, map[string]string{"id": host.ID})
+}
+
+var hostStatuses = []string{
+	mackerel.HostStatusWorking,
+	mackerel.HostStatusStandby,
+	mackerel.HostStatusMaintenance,
+	mackerel.HostStatusPoweroff,
+}
+
+func (s *Server) updateHostStatus(w http.ResponseWriter, r *http.Request) {
+	h, ok := s.workingHost(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	var param struct {
+		Status string `json:"status"`
+	}
+	if !decodeBody(w, r, &param) {
+		return
+	}
+	if !slices.Contains(hostStatuses, param.Status) {
+		writeError(w, http.StatusBadRequest, "invalid status")
+		return
+	}
+	host := *h
+	host.Status = param.Status
+	s.putHost(&host)
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *Server) bulkUpdateHostStatuses(w http.ResponseWriter, r *http.Request) {
+	var param struct {
+		IDs    []string `json:"ids"`
+		Status string   `json:"status"`
+	}
+	if !decodeBody(w, r, &param) {
+		return
+	}
+	if !slices.Contains(hostStatuses, param.Status) {
+		writeError(w, http.StatusBadRequest, "invalid status")
+		return
+	}
+	for _, id := range param.IDs {
+		if _, ok := s.workingHost(w, id); !ok {
+			return
+		}
+	}
+	for _, id := range param.IDs {
+		host := *s.hosts[id]
+		host.Status = param.Status
+		s.putHost(&host)
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *Server) updateHostRoleFullnames(w http.ResponseWriter, r *http.Request) {
+	h, ok := s.workingHost(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	var param struct {
+		RoleFullnames []string `json:"roleFullnames"`
+	}
+	if !decodeBody(w, r, &param) {
+		return
+	}
+	roles, ok := parseRoleFullnames(param.RoleFullnames)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid roleFullnames")
+		return
+	}
+	host := *h
+	host.Roles = roles
+	s.putHost(&host)
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *Server) retireHost(w http.ResponseWriter, r *http.Request) {
+	h, ok := s.workingHost(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	host := *h
+	host.IsRetired = true
+	s.putHost(&host)
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *Server) bulkRetireHosts(w http.ResponseWriter, r *http.Request) {
+	var param struct {
+		IDs []string `json:"ids"`
+	}
+	if !decodeBody(w, r, &param) {
+		return
+	}
+	for _, id := range param.IDs {
+		if _, ok := s.workingHost(w, id); !ok {
+			return
+		}
+	}
+	for _, id := range param.IDs {
+		host := *s.hosts[id]
+		host.IsRetired = true
+		s.putHost(&host)
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *Server) listHostMetricNames(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.hosts[id]; !ok {
+		writeError(w, http.StatusNotFound, "Host Not Found.")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"names": sortedKeys(s.hostMetrics[id])})
+}
+
+func (s *Server) findHostByCustomIdentifier(w http.ResponseWriter, r *http.Request) {
+	ci := r.PathValue("customIdentifier")
+	caseInsensitive := r.URL.Query().Get("caseInsensitive") == "true"
+	for _, id := range s.hostIDs {
+		h := s.hosts[id]
+		if h.IsRetired {
+			continue
+		}
+		if h.CustomIdentifier == ci || caseInsensitive && strings.EqualFold(h.CustomIdentifier, ci) {
+			writeJSON(w, http.StatusOK, map[string]any{"host": h})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Host Not Found.")
+}
@@ -0,0 +1,108 @@
+package mackereltest
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+type metadata struct {
+	value        json.RawMessage
+	lastModified time.Time
+}
+
+// metadataTarget resolves the owner of metadata from a request and returns the key prefix of it.
+type metadataTarget func(s *Server, w http.ResponseWriter, r *http.Request) (string, bool)
+
+func (s *Server) routeMetadata() {
+	s.handleMetadata("/api/v0/hosts/{id}/metadata", func(s *Server, w http.ResponseWriter, r *http.Request) (string, bool) {
+		id := r.PathValue("id")
+		if _, ok := s.hosts[id]; !ok {
+			writeError(w, http.StatusNotFound, "Host Not Found.")
+			return "", false
+		}
+		return "hosts/" + id + "/", true
+	})
+	s.handleMetadata("/api/v0/services/{service}/metadata", func(s *Server, w http.ResponseWriter, r *http.Request) (string, bool) {
+		name := r.PathValue("service")
+		if _, ok := s.services[name]; !ok {
+			writeError(w, http.StatusNotFound, "Service Not Found.")
+			return "", false
+		}
+		return "services/" + name + "/", true
+	})
+	s.handleMetadata("/api/v0/services/{service}/roles/{role}/metadata", func(s *Server, w http.ResponseWriter, r *http.Request) (string, bool) {
+		name, role := r.PathValue("service"), r.PathValue("role")
+		sv, ok := s.services[name]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Service Not Found.")
+			return "", false
+		}
+		if _, ok := sv.role(role); !ok {
+			writeError(w, http.StatusNotFound, "Role Not Found.")
+			return "", false
+		}
+		return "roles/" + name + "/" + role + "/", true
+	})
+}
+
+func (s *Server) handleMetadata(path string, target metadataTarget) {
+	s.handle("GET "+path, func(w http.ResponseWriter, r *http.Request) {
+		prefix, ok := target(s, w, r)
+		if !ok {
+			return
+		}
+		var namespaces []string
+		for key := range s.metadata {
+			if ns, ok := strings.CutPrefix(key, prefix); ok {
+				namespaces = append(namespaces, ns)
+			}
+		}
+		slices.Sort(namespaces)
+		list := make([]map[string]string, len(namespaces))
+		for i, ns := range namespaces {
+			list[i] = map[string]string{"namespace": ns}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"metadata": list})
+	})
+	s.handle("GET "+path+"/{namespace}", func(w http.ResponseWriter, r *http.Request) {
+		prefix, ok := target(s, w, r)
+		if !ok {
+			return
+		}
+		m, ok := s.metadata[prefix+r.PathValue("namespace")]
+		if !ok {
+			writeNotFound(w, "Metadata")
+			return
+		}
+		w.Header().Set("Last-Modified", m.lastModified.UTC().Format(http.TimeFormat))
+		writeJSON(w, http.StatusOK, m.value)
+	})
+	s.handle("PUT "+path+"/{namespace}", func(w http.ResponseWriter, r *http.Request) {
+		prefix, ok := target(s, w, r)
+		if !ok {
+			return
+		}
+		var value json.RawMessage
+		if !decodeBody(w, r, &value) {
+			return
+		}
+		s.metadata[prefix+r.PathValue("namespace")] = &metadata{value: value, lastModified: s.now()}
+		writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+	})
+	s.handle("DELETE "+path+"/{namespace}", func(w http.ResponseWriter, r *http.Request) {
+		prefix, ok := target(s, w, r)
+		if !ok {
+			return
+		}
+		key := prefix + r.PathValue("namespace")
+		if _, ok := s.metadata[key]; !ok {
+			writeNotFound(w, "Metadata")
+			return
+		}
+		delete(s.metadata, key)
+		writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+	})
+}
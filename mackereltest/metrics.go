@@ -0,0 +1,149 @@
+package mackereltest
+
+import (
+	"cmp"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/mackerelio/mackerel-client-go"
+)
+
+func (s *Server) routeMetrics() {
+	s.handle("POST /api/v0/tsdb", s.postHostMetricValues)
+	s.handle("POST /api/v0/services/{service}/tsdb", s.postServiceMetricValues)
+	s.handle("GET /api/v0/tsdb/latest", s.fetchLatestMetricValues)
+	s.handle("GET /api/v0/hosts/{id}/metrics", s.fetchHostMetricValues)
+	s.handle("GET /api/v0/services/{service}/metrics", s.fetchServiceMetricValues)
+}
+
+// HostMetricValues returns the values of the host metric posted to s, in the order they are posted.
+func (s *Server) HostMetricValues(hostID, name string) []*mackerel.MetricValue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.hostMetrics[hostID][name])
+}
+
+// ServiceMetricValues returns the values of the service metric posted to s, in the order they are posted.
+func (s *Server) ServiceMetricValues(serviceName, name string) []*mackerel.MetricValue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.serviceMetrics[serviceName][name])
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+func addMetricValue(metrics map[string]map[string][]*mackerel.MetricValue, key string, v *mackerel.MetricValue) {
+	if metrics[key] == nil {
+		metrics[key] = make(map[string][]*mackerel.MetricValue)
+	}
+	metrics[key][v.Name] = append(metrics[key][v.Name], v)
+}
+
+func validMetricValue(v *mackerel.MetricValue) bool {
+	return v != nil && v.Name != "" && v.Time > 0 && v.Value != nil
+}
+
+func (s *Server) postHostMetricValues(w http.ResponseWriter, r *http.Request) {
+	var values []*mackerel.HostMetricValue
+	if !decodeBody(w, r, &values) {
+		return
+	}
+	for _, v := range values {
+		if v.HostID == "" || !validMetricValue(v.MetricValue) {
+			writeError(w, http.StatusBadRequest, "invalid metric value")
+			return
+		}
+		if _, ok := s.hosts[v.HostID]; !ok {
+			writeError(w, http.StatusBadRequest, "Host Not Found: "+v.HostID)
+			return
+		}
+	}
+	for _, v := range values {
+		addMetricValue(s.hostMetrics, v.HostID, v.MetricValue)
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *Server) postServiceMetricValues(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("service")
+	if _, ok := s.services[name]; !ok {
+		writeError(w, http.StatusNotFound, "Service Not Found.")
+		return
+	}
+	var values []*mackerel.MetricValue
+	if !decodeBody(w, r, &values) {
+		return
+	}
+	for _, v := range values {
+		if !validMetricValue(v) {
+			writeError(w, http.StatusBadRequest, "invalid metric value")
+			return
+		}
+	}
+	for _, v := range values {
+		addMetricValue(s.serviceMetrics, name, v)
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *Server) fetchLatestMetricValues(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	latest := make(mackerel.LatestMetricValues)
+	for _, hostID := range q["hostId"] {
+		latest[hostID] = make(map[string]*mackerel.MetricValue)
+		for _, name := range q["name"] {
+			var v *mackerel.MetricValue
+			for _, mv := range s.hostMetrics[hostID][name] {
+				if v == nil || mv.Time >= v.Time {
+					v = mv
+				}
+			}
+			latest[hostID][name] = v
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"tsdbLatest": latest})
+}
+
+func (s *Server) fetchHostMetricValues(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.hosts[id]; !ok {
+		writeError(w, http.StatusNotFound, "Host Not Found.")
+		return
+	}
+	writeMetricValues(w, r, s.hostMetrics[id])
+}
+
+func (s *Server) fetchServiceMetricValues(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("service")
+	if _, ok := s.services[name]; !ok {
+		writeError(w, http.StatusNotFound, "Service Not Found.")
+		return
+	}
+	writeMetricValues(w, r, s.serviceMetrics[name])
+}
+
+func writeMetricValues(w http.ResponseWriter, r *http.Request, metrics map[string][]*mackerel.MetricValue) {
+	q := r.URL.Query()
+	from, err := strconv.ParseInt(q.Get("from"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid from")
+		return
+	}
+	to, err := strconv.ParseInt(q.Get("to"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid to")
+		return
+	}
+	values := []*mackerel.MetricValue{}
+	for _, v := range metrics[q.Get("name")] {
+		if from <= v.Time && v.Time <= to {
+			values = append(values, v)
+		}
+	}
+	slices.SortStableFunc(values, func(a, b *mackerel.MetricValue) int { return cmp.Compare(a.Time, b.Time) })
+	writeJSON(w, http.StatusOK, map[string]any{"metrics": values})
+}
@@ -0,0 +1,21 @@
+package mackereltest
+
+import "net/http"
+
+func (s *Server) routeMonitors() {
+	s.handleObjects(&objectRoutes{
+		store: s.monitors, path: "/api/v0/monitors", key: "monitors", kind: "Monitor", getOne: true, oneKey: "monitor",
+		prepare: func(obj, old map[string]any) (int, string) {
+			if old != nil {
+				obj["type"] = old["type"]
+			}
+			if t, _ := obj["type"].(string); t == "" {
+				return http.StatusBadRequest, "type is required"
+			}
+			if _, ok := obj["isMute"]; !ok {
+				obj["isMute"] = false
+			}
+			return 0, ""
+		},
+	})
+}
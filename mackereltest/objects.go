@@ -0,0 +1,153 @@
+package mackereltest
+
+import (
+	"net/http"
+	"slices"
+)
+
+// objectStore keeps JSON objects by their IDs in the order of creation.
+type objectStore struct {
+	ids  []string
+	objs map[string]map[string]any
+}
+
+func newObjectStore() *objectStore {
+	return &objectStore{objs: make(map[string]map[string]any)}
+}
+
+func (st *objectStore) list() []map[string]any {
+	objs := make([]map[string]any, 0, len(st.ids))
+	for _, id := range st.ids {
+		objs = append(objs, st.objs[id])
+	}
+	return objs
+}
+
+func (st *objectStore) get(id string) (map[string]any, bool) {
+	obj, ok := st.objs[id]
+	return obj, ok
+}
+
+func (st *objectStore) put(id string, obj map[string]any) {
+	obj["id"] = id
+	if _, ok := st.objs[id]; !ok {
+		st.ids = append(st.ids, id)
+	}
+	st.objs[id] = obj
+}
+
+func (st *objectStore) delete(id string) (map[string]any, bool) {
+	obj, ok := st.objs[id]
+	if !ok {
+		return nil, false
+	}
+	delete(st.objs, id)
+	st.ids = slices.DeleteFunc(st.ids, func(s string) bool { return s == id })
+	return obj, true
+}
+
+// objectRoutes describes CRUD endpoints of a kind of objects.
+type objectRoutes struct {
+	store *objectStore
+	// path is the collection path such as "/api/v0/downtimes".
+	path string
+	// key is the name of the list in the response of the collection.
+	key string
+	// kind is used in error messages.
+	kind string
+	// getOne registers GET of a single object.
+	getOne bool
+	// oneKey, if set, is the name of the object in the response of GET of a single object.
+	oneKey string
+	// prepare, if set, validates and completes obj on creation and update.
+	prepare func(obj, old map[string]any) (int, string)
+}
+
+func (s *Server) routeObjects() {
+	s.handleObjects(&objectRoutes{store: s.downtimes, path: "/api/v0/downtimes", key: "downtimes", kind: "Downtime"})
+	s.handleObjects(&objectRoutes{store: s.channels, path: "/api/v0/channels", key: "channels", kind: "Channel"})
+	s.handleObjects(&objectRoutes{
+		store: s.notificationGroups, path: "/api/v0/notification-groups", key: "notificationGroups", kind: "Notification group",
+		prepare: func(obj, old map[string]any) (int, string) {
+			obj["type"] = "group"
+			if old != nil {
+				obj["type"] = old["type"]
+			}
+			return 0, ""
+		},
+	})
+	s.handleObjects(&objectRoutes{
+		store: s.dashboards, path: "/api/v0/dashboards", key: "dashboards", kind: "Dashboard", getOne: true,
+		prepare: func(obj, old map[string]any) (int, string) {
+			now := s.unixNow()
+			obj["createdAt"] = now
+			if old != nil {
+				obj["createdAt"] = old["createdAt"]
+			}
+			obj["updatedAt"] = now
+			return 0, ""
+		},
+	})
+}
+
+func (s *Server) handleObjects(o *objectRoutes) {
+	s.handle("GET "+o.path, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{o.key: o.store.list()})
+	})
+	s.handle("POST "+o.path, func(w http.ResponseWriter, r *http.Request) {
+		var obj map[string]any
+		if !decodeBody(w, r, &obj) {
+			return
+		}
+		if o.prepare != nil {
+			if status, msg := o.prepare(obj, nil); status != 0 {
+				writeError(w, status, msg)
+				return
+			}
+		}
+		o.store.put(newID(), obj)
+		writeJSON(w, http.StatusOK, obj)
+	})
+	if o.getOne {
+		s.handle("GET "+o.path+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+			obj, ok := o.store.get(r.PathValue("id"))
+			if !ok {
+				writeNotFound(w, o.kind)
+				return
+			}
+			if o.oneKey != "" {
+				writeJSON(w, http.StatusOK, map[string]any{o.oneKey: obj})
+				return
+			}
+			writeJSON(w, http.StatusOK, obj)
+		})
+	}
+	s.handle("PUT "+o.path+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		old, ok := o.store.get(id)
+		if !ok {
+			writeNotFound(w, o.kind)
+			return
+		}
+		var obj map[string]any
+		if !decodeBody(w, r, &obj) {
+			return
+		}
+		if o.prepare != nil {
+			if status, msg := o.prepare(obj, old); status != 0 {
+				writeError(w, status, msg)
+				return
+			}
+		}
+		o.store.put(id, obj)
+		writeJSON(w, http.StatusOK, obj)
+	})
+	s.handle("DELETE "+o.path+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		obj, ok := o.store.delete(r.PathValue("id"))
+		if !ok {
+			writeNotFound(w, o.kind)
+			return
+		}
+		writeJSON(w, http.StatusOK, obj)
+	})
+}
@@ -0,0 +1,244 @@
+package mackereltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// RecorderMode decides whether [Recorder] records or replays interactions.
+type RecorderMode int
+
+// RecorderModes
+const (
+	// RecorderModeReplay replays the recorded interactions without sending requests.
+	RecorderModeReplay RecorderMode = iota
+	// RecorderModeRecord sends requests and records the interactions.
+	RecorderModeRecord
+)
+
+const redacted = "REDACTED"
+
+// redactedHeaders are the headers of requests and responses not to be written to cassettes.
+var redactedHeaders = []string{"X-Api-Key", "Authorization"}
+
+// Interaction is a pair of a request and its response in a cassette.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request in a cassette.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response in a cassette.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+type cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Recorder is an [http.RoundTripper] that records interactions with the API to a cassette file
+// and replays them later, which makes tests deterministic and runnable without network access.
+// API keys are redacted from the recorded requests and responses.
+//
+// Requests are matched by method, path, query and body, where JSON bodies are compared
+// regardless of formatting and the order of object keys.
+// Identical requests are answered with the recorded responses in order.
+type Recorder struct {
+	path      string
+	mode      RecorderMode
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+// NewRecorder returns a new Recorder of the cassette file at path.
+// In RecorderModeReplay, the file is loaded and must exist.
+// In RecorderModeRecord, requests are sent via transport, or [http.DefaultTransport] if it is nil,
+// and the file is written by Save.
+func NewRecorder(path string, mode RecorderMode, transport http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode, transport: transport}
+	if r.transport == nil {
+		r.transport = http.DefaultTransport
+	}
+	if mode == RecorderModeReplay {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var c cassette
+		if err := json.Unmarshal(b, &c); err != nil {
+			return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+		}
+		r.interactions = c.Interactions
+		r.used = make([]bool, len(c.Interactions))
+	}
+	return r, nil
+}
+
+// HTTPClient returns a new [http.Client] that uses r as the transport, to be set to Client.HTTPClient.
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close() // nolint
+		if err != nil {
+			return nil, err
+		}
+	}
+	recorded := RecordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.Query().Encode(),
+		Header: redactHeader(req.Header),
+		Body:   string(body),
+	}
+	if r.mode == RecorderModeReplay {
+		return r.replay(req, &recorded)
+	}
+	return r.record(req, body, &recorded)
+}
+
+func (r *Recorder) replay(req *http.Request, recorded *RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.interactions {
+		if r.used[i] || !in.Request.match(recorded) {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        strconv.Itoa(in.Response.StatusCode) + " " + http.StatusText(in.Response.StatusCode),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader([]byte(in.Response.Body))),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no recorded interaction matches %s %s in %s", req.Method, req.URL.RequestURI(), r.path)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte, recorded *RecordedRequest) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, &Interaction{
+		Request: *recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     redactHeader(resp.Header),
+			Body:       string(respBody),
+		},
+	})
+	return resp, nil
+}
+
+// Save writes the recorded interactions to the cassette file. It does nothing in RecorderModeReplay.
+func (r *Recorder) Save() error {
+	if r.mode == RecorderModeReplay {
+		return nil
+	}
+	r.mu.Lock()
+	c := cassette{Interactions: r.interactions}
+	b, err := json.MarshalIndent(&c, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(b, '\n'), 0o644)
+}
+
+// Unused returns the recorded interactions that have not been replayed,
+// which is useful to assert that the code under test sent all the expected requests.
+// It returns nil in RecorderModeRecord.
+func (r *Recorder) Unused() []*Interaction {
+	if r.mode != RecorderModeReplay {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []*Interaction
+	for i, in := range r.interactions {
+		if !r.used[i] {
+			unused = append(unused, in)
+		}
+	}
+	return unused
+}
+
+func redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range redactedHeaders {
+		if h.Get(k) != "" {
+			h.Set(k, redacted)
+		}
+	}
+	return h
+}
+
+func (rr *RecordedRequest) match(req *RecordedRequest) bool {
+	return rr.Method == req.Method && rr.Path == req.Path && rr.Query == req.Query &&
+		bytes.Equal(normalizeBody(rr.Body), normalizeBody(req.Body))
+}
+
+// normalizeBody returns body re-encoded if it is JSON, so that bodies are compared regardless of formatting.
+// Object keys are sorted by encoding/json.
+func normalizeBody(body string) []byte {
+	var v any
+	dec := json.NewDecoder(bytes.NewReader([]byte(body)))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return []byte(body)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return []byte(body)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return []byte(body)
+	}
+	return b
+}
@@ -0,0 +1,125 @@
+package mackereltest
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mackerelio/mackerel-client-go"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	s := NewServer()
+	s.APIKey = "secret-api-key"
+	rec, err := NewRecorder(path, RecorderModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := s.Client()
+	client.HTTPClient = rec.HTTPClient()
+	created, err := client.CreateServiceContext(t.Context(), &mackerel.CreateServiceParam{Name: "My-Service", Memo: "memo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.FindServicesContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if unused := rec.Unused(); unused != nil {
+		t.Error("Unused should be nil while recording but: ", unused)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "secret-api-key") {
+		t.Error("API key should be redacted but: ", string(b))
+	}
+
+	rec, err = NewRecorder(path, RecorderModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ = mackerel.NewClientWithOptions("another-key", s.URL, false)
+	client.HTTPClient = rec.HTTPClient()
+	// The body differs from the recorded one only in the order of keys.
+	resp, err := client.PostJSON("/api/v0/services", map[string]string{"memo": "memo", "name": "My-Service"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close() // nolint
+	if resp.StatusCode != http.StatusOK {
+		t.Error("status code should be 200 but: ", resp.StatusCode)
+	}
+	services, err := client.FindServicesContext(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 || services[0].Name != created.Name {
+		t.Error("services should be replayed but: ", services)
+	}
+	if unused := rec.Unused(); len(unused) != 0 {
+		t.Error("all interactions should be replayed but: ", unused)
+	}
+	if _, err := client.FindServicesContext(t.Context()); err == nil {
+		t.Error("request without a remaining interaction should fail")
+	}
+}
+
+type headerTransport http.Header
+
+func (h headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header(h).Clone(), Body: http.NoBody, Request: req}, nil
+}
+
+func TestRecorder_RedactResponseHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := NewRecorder(path, RecorderModeRecord, headerTransport{"X-Api-Key": {"secret-api-key"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rec.HTTPClient().Get("http://example.com/api/v0/services")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close() // nolint
+	if resp.Header.Get("X-Api-Key") != "secret-api-key" {
+		t.Error("the response itself should not be redacted but: ", resp.Header)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "secret-api-key") {
+		t.Error("API key in response headers should be redacted but: ", string(b))
+	}
+}
+
+func TestNormalizeBody(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		equal bool
+	}{
+		{`{"a":1,"b":[1,2]}`, "{\n  \"b\": [1, 2],\n  \"a\": 1\n}", true},
+		{`{"a":1}`, `{"a":2}`, false},
+		{`{"a":1.0}`, `{"a":1}`, false},
+		{`not json`, `not json`, true},
+		{``, ``, true},
+	}
+	for _, tt := range tests {
+		if got := string(normalizeBody(tt.a)) == string(normalizeBody(tt.b)); got != tt.equal {
+			t.Errorf("normalizeBody(%q) == normalizeBody(%q) should be %t", tt.a, tt.b, tt.equal)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// Package mackereltest provides an in-memory fake of Mackerel API for tests.
+//
+// The fake keeps its state across requests, so objects created through
+// [mackerel.Client] can be read back, updated and deleted.
+// It also supports fault injection and recording of the received requests.
+package mackereltest
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mackerelio/mackerel-client-go"
+)
+
+// Server is an in-memory fake of Mackerel API.
+// It is safe for concurrent use.
+type Server struct {
+	// URL is the base URL of the server, set by NewServer.
+	URL string
+
+	// APIKey, if set, is required in X-Api-Key header of each request.
+	APIKey string
+
+	ts  *httptest.Server
+	mux *http.ServeMux
+	now func() time.Time
+
+	mu       sync.Mutex
+	faults   []*Fault
+	requests []*Request
+
+	hosts              map[string]*mackerel.Host
+	hostIDs            []string
+	services           map[string]*service
+	serviceNames       []string
+	monitors           *objectStore
+	downtimes          *objectStore
+	dashboards         *objectStore
+	channels           *objectStore
+	notificationGroups *objectStore
+	alerts             map[string]*mackerel.Alert
+	alertIDs           []string
+	alertLogs          map[string][]*mackerel.AlertLog
+	metadata           map[string]*metadata
+	hostMetrics        map[string]map[string][]*mackerel.MetricValue
+	serviceMetrics     map[string]map[string][]*mackerel.MetricValue
+}
+
+// Request is a request received by [Server].
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Fault describes a failure that [Server] injects into matching requests.
+type Fault struct {
+	// Method and Path select the requests to inject the fault.
+	// Empty Method matches any method. Path matches the request path exactly,
+	// or as a prefix when it ends with "/".
+	Method string
+	Path   string
+
+	// Latency delays the response.
+	Latency time.Duration
+	// StatusCode, if not zero, is returned instead of the normal response.
+	StatusCode int
+	// Message is the error message returned with StatusCode.
+	Message string
+	// Header is added to the response, such as Retry-After.
+	Header http.Header
+	// Times limits how many requests the fault applies to. Zero means unlimited.
+	Times int
+}
+
+func (f *Fault) match(r *http.Request) bool {
+	if f.Method != "" && f.Method != r.Method {
+		return false
+	}
+	if strings.HasSuffix(f.Path, "/") {
+		return strings.HasPrefix(r.URL.Path, f.Path)
+	}
+	return f.Path == "" || f.Path == r.URL.Path
+}
+
+// NewServer starts and returns a new Server.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := NewUnstartedServer()
+	s.ts = httptest.NewServer(s)
+	s.URL = s.ts.URL
+	return s
+}
+
+// NewUnstartedServer returns a new Server that is not listening.
+// It can be used as an [http.Handler], for instance with [http.Client] of a custom transport.
+func NewUnstartedServer() *Server {
+	s := &Server{
+		now:                time.Now,
+		hosts:              make(map[string]*mackerel.Host),
+		services:           make(map[string]*service),
+		monitors:           newObjectStore(),
+		downtimes:          newObjectStore(),
+		dashboards:         newObjectStore(),
+		channels:           newObjectStore(),
+		notificationGroups: newObjectStore(),
+		alerts:             make(map[string]*mackerel.Alert),
+		alertLogs:          make(map[string][]*mackerel.AlertLog),
+		metadata:           make(map[string]*metadata),
+		hostMetrics:        make(map[string]map[string][]*mackerel.MetricValue),
+		serviceMetrics:     make(map[string]map[string][]*mackerel.MetricValue),
+	}
+	s.mux = http.NewServeMux()
+	s.routeHosts()
+	s.routeServices()
+	s.routeMonitors()
+	s.routeAlerts()
+	s.routeObjects()
+	s.routeMetadata()
+	s.routeMetrics()
+	s.mux.HandleFunc("/", notImplemented)
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	if s.ts != nil {
+		s.ts.Close()
+	}
+}
+
+// Client returns a new client that sends requests to s.
+func (s *Server) Client() *mackerel.Client {
+	apiKey := s.APIKey
+	if apiKey == "" {
+		apiKey = "dummy-key"
+	}
+	c, err := mackerel.NewClientWithOptions(apiKey, s.URL, false)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// InjectFault adds a fault. Faults are examined in the order they are added.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all the faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the requests received so far, in the order they are received.
+func (s *Server) Requests() []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Request(nil), s.requests...)
+}
+
+// RequestsTo returns the received requests that have method and path.
+func (s *Server) RequestsTo(method, path string) []*Request {
+	var reqs []*Request
+	for _, r := range s.Requests() {
+		if r.Method == method && r.Path == path {
+			reqs = append(reqs, r)
+		}
+	}
+	return reqs
+}
+
+// ServeHTTP implements [http.Handler].
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	s.mu.Lock()
+	s.requests = append(s.requests, &Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	fault := s.takeFault(r)
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Latency > 0 {
+			select {
+			case <-time.After(fault.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		for k, vs := range fault.Header {
+			for _, v := range vs {
+				w.Header().Add(k, v)
+			}
+		}
+		if fault.StatusCode != 0 {
+			writeError(w, fault.StatusCode, cmp.Or(fault.Message, http.StatusText(fault.StatusCode)))
+			return
+		}
+	}
+	if s.APIKey != "" && r.Header.Get("X-Api-Key") != s.APIKey {
+		writeError(w, http.StatusUnauthorized, "Authentication failed. Please try with valid Api Key.")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// takeFault returns the first fault matching r. s.mu must be held.
+func (s *Server) takeFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if !f.match(r) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// handle registers a handler that runs with s.mu held.
+func (s *Server) handle(pattern string, h func(w http.ResponseWriter, r *http.Request)) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		h(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v) // nolint
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"error": map[string]string{"message": message}})
+}
+
+func writeNotFound(w http.ResponseWriter, kind string) {
+	writeError(w, http.StatusNotFound, kind+" not found")
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return false
+	}
+	return true
+}
+
+const idChars = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// newID returns a random ID that looks like those of Mackerel.
+func newID() string {
+	b := make([]byte, 11)
+	rand.Read(b) // nolint
+	for i := range b {
+		b[i] = idChars[int(b[i])%len(idChars)]
+	}
+	return string(b)
+}
+
+func (s *Server) unixNow() int64 {
+	return s.now().Unix()
+}
+
+func notImplemented(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotImplemented, fmt.Sprintf("%s %s is not implemented in mackereltest", r.Method, r.URL.Path))
+}
@@ -0,0 +1,176 @@
+package mackereltest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mackerelio/mackerel-client-go"
+)
+
+func TestServer_Monitors(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	client := s.Client()
+
+	created, err := client.CreateMonitorContext(t.Context(), &mackerel.MonitorHostMetric{
+		Name:     "cpu",
+		Type:     "host",
+		Metric:   "cpu.user.percentage",
+		Operator: ">",
+		Duration: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.MonitorID() == "" {
+		t.Error("created monitor should have an ID")
+	}
+	monitors, err := client.FindMonitorsContext(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(monitors) != 1 || monitors[0].MonitorName() != "cpu" || monitors[0].MonitorType() != "host" {
+		t.Error("monitors should have the created monitor but: ", monitors)
+	}
+	m, err := client.GetMonitorContext(t.Context(), created.MonitorID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.(*mackerel.MonitorHostMetric).Metric != "cpu.user.percentage" {
+		t.Error("metric should be cpu.user.percentage but: ", m)
+	}
+	if _, err := client.DeleteMonitorContext(t.Context(), created.MonitorID()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetMonitorContext(t.Context(), created.MonitorID()); !errors.Is(err, mackerel.ErrNotFound) {
+		t.Error("deleted monitor should not be found but: ", err)
+	}
+}
+
+func TestServer_Hosts(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	client := s.Client()
+
+	id, err := client.CreateHostContext(t.Context(), &mackerel.CreateHostParam{
+		Name:             "web01",
+		CustomIdentifier: "i-0123456789",
+		RoleFullnames:    []string{"My-Service:proxy"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := client.FindHostsContext(t.Context(), &mackerel.FindHostsParam{Service: "My-Service", Roles: []string{"proxy"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].ID != id || hosts[0].Status != mackerel.HostStatusWorking {
+		t.Error("hosts should have the created host but: ", hosts)
+	}
+	if _, err := client.CreateHostContext(t.Context(), &mackerel.CreateHostParam{Name: "web02", CustomIdentifier: "i-0123456789"}); !errors.Is(err, mackerel.ErrConflict) {
+		t.Error("duplicate custom identifier should conflict but: ", err)
+	}
+	services, err := client.FindServicesContext(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 || services[0].Name != "My-Service" || len(services[0].Roles) != 1 {
+		t.Error("services should have the role of the host but: ", services)
+	}
+
+	if err := client.PutHostMetaDataContext(t.Context(), id, "inventory", map[string]string{"rack": "A1"}); err != nil {
+		t.Fatal(err)
+	}
+	meta, err := client.GetHostMetaDataContext(t.Context(), id, "inventory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.HostMetaData.(map[string]any)["rack"] != "A1" || meta.LastModified.IsZero() {
+		t.Error("metadata should be returned with Last-Modified but: ", meta)
+	}
+
+	now := time.Now().Unix()
+	if err := client.PostHostMetricValuesByHostIDContext(t.Context(), id, []*mackerel.MetricValue{{Name: "custom.foo", Time: now, Value: 1.5}}); err != nil {
+		t.Fatal(err)
+	}
+	if values := s.HostMetricValues(id, "custom.foo"); len(values) != 1 || values[0].Value != 1.5 {
+		t.Error("posted metric values should be stored but: ", values)
+	}
+
+	if err := client.RetireHostContext(t.Context(), id); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err = client.FindHostsContext(t.Context(), &mackerel.FindHostsParam{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 0 {
+		t.Error("retired hosts should not be found but: ", hosts)
+	}
+}
+
+func TestServer_Alerts(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	client := s.Client()
+
+	for range 3 {
+		s.AddAlert(&mackerel.Alert{Status: "CRITICAL", Type: "connectivity"})
+	}
+	var n int
+	for alert, err := range client.AlertsSeq(t.Context(), false, &mackerel.PageOptions{PageSize: 2}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n == 0 {
+			if _, err := client.CloseAlertContext(t.Context(), alert.ID, "resolved"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		n++
+	}
+	if n != 3 {
+		t.Error("3 alerts should be yielded but: ", n)
+	}
+	resp, err := client.FindAlertsContext(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Alerts) != 2 {
+		t.Error("closed alert should not be found but: ", resp.Alerts)
+	}
+}
+
+func TestServer_Faults(t *testing.T) {
+	s := NewServer()
+	s.APIKey = "secret"
+	defer s.Close()
+	client := s.Client()
+	client.RetryPolicy = &mackerel.RetryPolicy{BaseBackoff: time.Millisecond}
+
+	s.InjectFault(Fault{
+		Method:     http.MethodGet,
+		Path:       "/api/v0/services",
+		StatusCode: http.StatusServiceUnavailable,
+		Times:      2,
+	})
+	if _, err := client.FindServicesContext(t.Context()); err != nil {
+		t.Fatal("request should succeed after retries but: ", err)
+	}
+	if reqs := s.RequestsTo(http.MethodGet, "/api/v0/services"); len(reqs) != 3 {
+		t.Error("3 requests should be received but: ", len(reqs))
+	}
+
+	s.InjectFault(Fault{Path: "/api/v0/", StatusCode: http.StatusTooManyRequests})
+	if _, err := client.FindServicesContext(t.Context()); !errors.Is(err, mackerel.ErrRateLimited) {
+		t.Error("request should be rate limited but: ", err)
+	}
+	s.ClearFaults()
+
+	unauthorized, _ := mackerel.NewClientWithOptions("wrong-key", s.URL, false)
+	if _, err := unauthorized.FindServicesContext(t.Context()); !errors.Is(err, mackerel.ErrUnauthorized) {
+		t.Error("request with a wrong API key should be unauthorized but: ", err)
+	}
+}
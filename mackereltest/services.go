@@ -0,0 +1,162 @@
+package mackereltest
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/mackerelio/mackerel-client-go"
+)
+
+type service struct {
+	name  string
+	memo  string
+	roles []*mackerel.Role
+}
+
+func (sv *service) toService() *mackerel.Service {
+	roles := make([]string, len(sv.roles))
+	for i, r := range sv.roles {
+		roles[i] = r.Name
+	}
+	return &mackerel.Service{Name: sv.name, Memo: sv.memo, Roles: roles}
+}
+
+func (sv *service) role(name string) (*mackerel.Role, bool) {
+	i := slices.IndexFunc(sv.roles, func(r *mackerel.Role) bool { return r.Name == name })
+	if i < 0 {
+		return nil, false
+	}
+	return sv.roles[i], true
+}
+
+func (s *Server) routeServices() {
+	s.handle("GET /api/v0/services", s.findServices)
+	s.handle("POST /api/v0/services", s.createService)
+	s.handle("DELETE /api/v0/services/{service}", s.deleteService)
+	s.handle("GET /api/v0/services/{service}/metric-names", s.listServiceMetricNames)
+	s.handle("GET /api/v0/services/{service}/roles", s.findRoles)
+	s.handle("POST /api/v0/services/{service}/roles", s.createRole)
+	s.handle("DELETE /api/v0/services/{service}/roles/{role}", s.deleteRole)
+}
+
+// ensureRole creates the service and the role unless they exist, as posting hosts does.
+func (s *Server) ensureRole(serviceName, roleName string) {
+	sv, ok := s.services[serviceName]
+	if !ok {
+		sv = &service{name: serviceName}
+		s.services[serviceName] = sv
+		s.serviceNames = append(s.serviceNames, serviceName)
+	}
+	if _, ok := sv.role(roleName); !ok {
+		sv.roles = append(sv.roles, &mackerel.Role{Name: roleName})
+	}
+}
+
+func (s *Server) findServices(w http.ResponseWriter, r *http.Request) {
+	services := make([]*mackerel.Service, 0, len(s.serviceNames))
+	for _, name := range s.serviceNames {
+		services = append(services, s.services[name].toService())
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"services": services})
+}
+
+func (s *Server) createService(w http.ResponseWriter, r *http.Request) {
+	var param mackerel.CreateServiceParam
+	if !decodeBody(w, r, &param) {
+		return
+	}
+	if param.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if _, ok := s.services[param.Name]; ok {
+		writeError(w, http.StatusBadRequest, "Service already exists.")
+		return
+	}
+	sv := &service{name: param.Name, memo: param.Memo}
+	s.services[sv.name] = sv
+	s.serviceNames = append(s.serviceNames, sv.name)
+	writeJSON(w, http.StatusOK, sv.toService())
+}
+
+func (s *Server) deleteService(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("service")
+	sv, ok := s.services[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Service Not Found.")
+		return
+	}
+	delete(s.services, name)
+	s.serviceNames = slices.DeleteFunc(s.serviceNames, func(n string) bool { return n == name })
+	delete(s.serviceMetrics, name)
+	for _, h := range s.hosts {
+		if _, ok := h.Roles[name]; ok {
+			host := *h
+			host.Roles = make(mackerel.Roles)
+			for k, v := range h.Roles {
+				if k != name {
+					host.Roles[k] = v
+				}
+			}
+			s.hosts[host.ID] = &host
+		}
+	}
+	writeJSON(w, http.StatusOK, sv.toService())
+}
+
+func (s *Server) listServiceMetricNames(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("service")
+	if _, ok := s.services[name]; !ok {
+		writeError(w, http.StatusNotFound, "Service Not Found.")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"names": sortedKeys(s.serviceMetrics[name])})
+}
+
+func (s *Server) findRoles(w http.ResponseWriter, r *http.Request) {
+	sv, ok := s.services[r.PathValue("service")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Service Not Found.")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"roles": sv.roles})
+}
+
+func (s *Server) createRole(w http.ResponseWriter, r *http.Request) {
+	sv, ok := s.services[r.PathValue("service")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Service Not Found.")
+		return
+	}
+	var param mackerel.CreateRoleParam
+	if !decodeBody(w, r, &param) {
+		return
+	}
+	if param.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if _, ok := sv.role(param.Name); ok {
+		writeError(w, http.StatusBadRequest, "Role already exists.")
+		return
+	}
+	role := &mackerel.Role{Name: param.Name, Memo: param.Memo}
+	sv.roles = append(sv.roles, role)
+	writeJSON(w, http.StatusOK, role)
+}
+
+func (s *Server) deleteRole(w http.ResponseWriter, r *http.Request) {
+	sv, ok := s.services[r.PathValue("service")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Service Not Found.")
+		return
+	}
+	name := r.PathValue("role")
+	role, ok := sv.role(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Role Not Found.")
+		return
+	}
+	sv.roles = slices.DeleteFunc(sv.roles, func(r *mackerel.Role) bool { return r.Name == name })
+	writeJSON(w, http.StatusOK, role)
+}
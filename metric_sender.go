@@ -0,0 +1,381 @@
+package mackerel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMetricSenderBatchSize     = 500
+	defaultMetricSenderBufferSize    = 10000
+	defaultMetricSenderFlushInterval = 10 * time.Second
+)
+
+// ErrMetricSenderClosed is returned when values are added to a closed [MetricSender].
+var ErrMetricSenderClosed = errors.New("metric sender is closed")
+
+// MetricDropPolicy decides what [MetricSender] does when its buffer is full.
+type MetricDropPolicy int
+
+// MetricDropPolicies
+const (
+	// MetricDropPolicyBlock blocks the caller until the buffer has room or its context is done.
+	MetricDropPolicyBlock MetricDropPolicy = iota
+	// MetricDropPolicyDropNewest drops the value being added.
+	MetricDropPolicyDropNewest
+	// MetricDropPolicyDropOldest drops the oldest buffered value to make room.
+	MetricDropPolicyDropOldest
+)
+
+// MetricSenderOptions configures [MetricSender].
+// Zero-valued fields fall back to their defaults.
+type MetricSenderOptions struct {
+	// BatchSize is the maximum number of values posted in a request. The default is 500.
+	BatchSize int
+	// BufferSize is the maximum number of values waiting to be posted. The default is 10000.
+	BufferSize int
+	// FlushInterval is the interval to post buffered values. The default is 10 seconds.
+	FlushInterval time.Duration
+	// DropPolicy decides what to do when the buffer is full. The default is MetricDropPolicyBlock.
+	DropPolicy MetricDropPolicy
+	// RetryPolicy controls retries of batches that failed to be posted.
+	// When nil, [DefaultRetryPolicy] is used.
+	RetryPolicy *RetryPolicy
+	// OnError is called with errors of batches that failed in background flushes.
+	OnError func(err error)
+	// Spool stores batches given up after retries, except those rejected by the API.
+	// The spooled batches are replayed on a later flush that succeeds.
+	Spool *MetricSpool
+}
+
+// MetricSenderStats is the statistics of [MetricSender].
+type MetricSenderStats struct {
+	// Sent is the number of values posted successfully.
+	Sent uint64
+	// Dropped is the number of values dropped because the buffer was full.
+	Dropped uint64
+	// Retried is the number of retried requests.
+	Retried uint64
+	// Failed is the number of values given up after retries.
+	Failed uint64
+	// Spooled is the number of values saved to Spool after retries.
+	Spooled uint64
+}
+
+type metricSenderEntry struct {
+	service string
+	host    *HostMetricValue
+	value   *MetricValue
+}
+
+// MetricSender buffers host and service metric values and posts them in batches.
+// It is safe for concurrent use.
+//
+// Buffered values are posted when the number of them reaches BatchSize,
+// every FlushInterval, on Flush and on Close.
+type MetricSender struct {
+	client *Client
+	opts   MetricSenderOptions
+
+	mu      sync.Mutex
+	queue   []metricSenderEntry
+	changed chan struct{}
+	closed  bool
+
+	sendMu  sync.Mutex
+	trigger chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	sent    atomic.Uint64
+	dropped atomic.Uint64
+	retried atomic.Uint64
+	failed  atomic.Uint64
+	spooled atomic.Uint64
+}
+
+// NewMetricSender returns a new MetricSender that posts values via client.
+// Callers must call Close to post the remaining values and release the resources.
+func NewMetricSender(client *Client, opts *MetricSenderOptions) *MetricSender {
+	var o MetricSenderOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = defaultMetricSenderBatchSize
+	}
+	if o.BufferSize <= 0 {
+		o.BufferSize = defaultMetricSenderBufferSize
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = defaultMetricSenderFlushInterval
+	}
+	if o.RetryPolicy == nil {
+		o.RetryPolicy = DefaultRetryPolicy()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &MetricSender{
+		client:  client,
+		opts:    o,
+		changed: make(chan struct{}),
+		trigger: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	go s.run()
+	return s
+}
+
+func (s *MetricSender) run() {
+	defer close(s.stopped)
+	t := time.NewTicker(s.opts.FlushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-t.C:
+		case <-s.trigger:
+		}
+		if err := s.Flush(s.ctx); err != nil && s.opts.OnError != nil {
+			s.opts.OnError(err)
+		}
+	}
+}
+
+// AddHostMetricValues adds host metric values to the buffer.
+func (s *MetricSender) AddHostMetricValues(ctx context.Context, values ...*HostMetricValue) error {
+	for _, v := range values {
+		if err := s.add(ctx, metricSenderEntry{host: v}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddServiceMetricValues adds service metric values of serviceName to the buffer.
+func (s *MetricSender) AddServiceMetricValues(ctx context.Context, serviceName string, values ...*MetricValue) error {
+	for _, v := range values {
+		if err := s.add(ctx, metricSenderEntry{service: serviceName, value: v}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MetricSender) add(ctx context.Context, e metricSenderEntry) error {
+	for {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return ErrMetricSenderClosed
+		}
+		if len(s.queue) < s.opts.BufferSize {
+			s.queue = append(s.queue, e)
+			full := len(s.queue) >= s.opts.BatchSize
+			s.mu.Unlock()
+			if full {
+				s.notify()
+			}
+			return nil
+		}
+		switch s.opts.DropPolicy {
+		case MetricDropPolicyDropNewest:
+			s.mu.Unlock()
+			s.dropped.Add(1)
+			return nil
+		case MetricDropPolicyDropOldest:
+			s.queue = append(s.queue[1:], e)
+			s.mu.Unlock()
+			s.dropped.Add(1)
+			return nil
+		}
+		changed := s.changed
+		s.mu.Unlock()
+		s.notify()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (s *MetricSender) notify() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// take removes all the buffered values and returns them.
+func (s *MetricSender) take() []metricSenderEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := s.queue
+	s.queue = nil
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return q
+}
+
+// Flush posts all the buffered values and waits for them to finish.
+// It returns the errors of batches that failed after retries.
+func (s *MetricSender) Flush(ctx context.Context) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	var (
+		hostValues    []*HostMetricValue
+		serviceValues = make(map[string][]*MetricValue)
+		services      []string
+	)
+	for _, e := range s.take() {
+		if e.host != nil {
+			hostValues = append(hostValues, e.host)
+			continue
+		}
+		if _, ok := serviceValues[e.service]; !ok {
+			services = append(services, e.service)
+		}
+		serviceValues[e.service] = append(serviceValues[e.service], e.value)
+	}
+
+	var (
+		errs         []error
+		spoolHost    func([]*HostMetricValue) error
+		spoolService func(string) func([]*MetricValue) error
+	)
+	if spool := s.opts.Spool; spool != nil {
+		spoolHost = spool.SaveHostMetricValues
+		spoolService = func(service string) func([]*MetricValue) error {
+			return func(values []*MetricValue) error {
+				return spool.SaveServiceMetricValues(service, values)
+			}
+		}
+	}
+	for batch := range slices.Chunk(hostValues, s.opts.BatchSize) {
+		errs = append(errs, sendMetricBatch(s, ctx, batch, s.client.PostHostMetricValuesContext, spoolHost))
+	}
+	for _, service := range services {
+		post := func(ctx context.Context, values []*MetricValue) error {
+			return s.client.PostServiceMetricValuesContext(ctx, service, values)
+		}
+		var save func([]*MetricValue) error
+		if spoolService != nil {
+			save = spoolService(service)
+		}
+		for batch := range slices.Chunk(serviceValues[service], s.opts.BatchSize) {
+			errs = append(errs, sendMetricBatch(s, ctx, batch, post, save))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	if s.opts.Spool != nil && ctx.Err() == nil {
+		// Post the values spooled during outages, including those of previous processes.
+		return s.opts.Spool.Replay(ctx, s.client)
+	}
+	return nil
+}
+
+// sendMetricBatch posts values with retries. A batch rejected as too large is split in half.
+// When it gives up and spool is not nil, values are saved to spool unless the API rejected them.
+func sendMetricBatch[T any](s *MetricSender, ctx context.Context, values []T, post func(context.Context, []T) error, spool func([]T) error) error {
+	giveUp := func(err error) error {
+		if spool != nil && !isRejectedMetricError(err) {
+			serr := spool(values)
+			if serr == nil {
+				s.spooled.Add(uint64(len(values)))
+				return nil
+			}
+			err = errors.Join(err, serr)
+		}
+		s.failed.Add(uint64(len(values)))
+		return err
+	}
+	for n := 1; ; n++ {
+		err := post(ctx, values)
+		if err == nil {
+			s.sent.Add(uint64(len(values)))
+			return nil
+		}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusRequestEntityTooLarge && len(values) > 1 {
+			half := len(values) / 2
+			return errors.Join(
+				sendMetricBatch(s, ctx, values[:half], post, spool),
+				sendMetricBatch(s, ctx, values[half:], post, spool),
+			)
+		}
+		if !isRetryableMetricError(err) || n >= s.opts.RetryPolicy.maxAttempts() || ctx.Err() != nil {
+			return giveUp(err)
+		}
+		if err := sleepContext(ctx, s.opts.RetryPolicy.backoff(n)); err != nil {
+			return giveUp(err)
+		}
+		s.retried.Add(1)
+	}
+}
+
+// isRejectedMetricError reports whether err means the API rejected the metrics.
+// Such requests fail again, so they are neither retried nor spooled.
+func isRejectedMetricError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 && apiErr.StatusCode != http.StatusTooManyRequests
+	}
+	return false
+}
+
+// isRetryableMetricError reports whether posting metrics should be retried after err.
+func isRetryableMetricError(err error) bool {
+	if isRejectedMetricError(err) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// Close stops accepting values, posts the buffered values and stops the background flushes.
+// When ctx is done, the remaining retries are abandoned.
+func (s *MetricSender) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.changed)
+	s.changed = make(chan struct{})
+	s.mu.Unlock()
+
+	close(s.done)
+	select {
+	case <-s.stopped:
+	case <-ctx.Done():
+		s.cancel()
+		<-s.stopped
+	}
+	defer s.cancel()
+	return s.Flush(ctx)
+}
+
+// Stats returns the statistics of s.
+func (s *MetricSender) Stats() MetricSenderStats {
+	return MetricSenderStats{
+		Sent:    s.sent.Load(),
+		Dropped: s.dropped.Load(),
+		Retried: s.retried.Load(),
+		Failed:  s.failed.Load(),
+		Spooled: s.spooled.Load(),
+	}
+}
@@ -0,0 +1,189 @@
+package mackerel
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type metricSenderTestServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	batches  map[string][][]json.RawMessage
+	failures int
+	maxBatch int
+}
+
+func newMetricSenderTestServer(t *testing.T) *metricSenderTestServer {
+	t.Helper()
+	s := &metricSenderTestServer{batches: make(map[string][][]json.RawMessage)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" {
+			t.Error("request method should be POST but: ", req.Method)
+		}
+		var values []json.RawMessage
+		if err := json.NewDecoder(req.Body).Decode(&values); err != nil {
+			t.Error(err)
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.failures > 0 {
+			s.failures--
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if s.maxBatch > 0 && len(values) > s.maxBatch {
+			res.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		s.batches[req.URL.Path] = append(s.batches[req.URL.Path], values)
+		res.Write([]byte(`{"success":true}`)) // nolint
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *metricSenderTestServer) batchSizes(path string) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sizes []int
+	for _, b := range s.batches[path] {
+		sizes = append(sizes, len(b))
+	}
+	return sizes
+}
+
+func sumInts(a []int) int {
+	var n int
+	for _, v := range a {
+		n += v
+	}
+	return n
+}
+
+func TestMetricSender(t *testing.T) {
+	ts := newMetricSenderTestServer(t)
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+	s := NewMetricSender(client, &MetricSenderOptions{BatchSize: 2, FlushInterval: time.Hour})
+
+	for i := range 5 {
+		err := s.AddHostMetricValues(t.Context(), &HostMetricValue{
+			HostID:      "9rxGOHfVF8F",
+			MetricValue: &MetricValue{Name: "custom.metric", Time: int64(1700000000 + i), Value: i},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := s.AddServiceMetricValues(t.Context(), "My-Service",
+		&MetricValue{Name: "proxy.access_log.latency", Time: 1700000000, Value: 500},
+		&MetricValue{Name: "proxy.access_log.latency", Time: 1700000060, Value: 600},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	hostSizes := ts.batchSizes("/api/v0/tsdb")
+	if n := sumInts(hostSizes); n != 5 {
+		t.Errorf("5 host metric values should be posted but %d", n)
+	}
+	for _, n := range hostSizes {
+		if n > 2 {
+			t.Errorf("batch size should be at most 2 but %v", hostSizes)
+		}
+	}
+	if n := sumInts(ts.batchSizes("/api/v0/services/My-Service/tsdb")); n != 2 {
+		t.Errorf("2 service metric values should be posted but %d", n)
+	}
+	if stats := s.Stats(); stats.Sent != 7 {
+		t.Errorf("Stats().Sent should be 7 but %+v", stats)
+	}
+	if err := s.AddHostMetricValues(t.Context(), &HostMetricValue{}); !errors.Is(err, ErrMetricSenderClosed) {
+		t.Errorf("AddHostMetricValues after Close should return ErrMetricSenderClosed but %v", err)
+	}
+}
+
+func TestMetricSender_RetryAndSplit(t *testing.T) {
+	ts := newMetricSenderTestServer(t)
+	ts.failures = 1
+	ts.maxBatch = 2
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+	s := NewMetricSender(client, &MetricSenderOptions{
+		BatchSize:     10,
+		FlushInterval: time.Hour,
+		RetryPolicy:   &RetryPolicy{BaseBackoff: time.Millisecond},
+	})
+	defer s.Close(t.Context()) // nolint
+
+	for i := range 4 {
+		err := s.AddServiceMetricValues(t.Context(), "My-Service", &MetricValue{Name: "custom.metric", Time: int64(i), Value: i})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Flush(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	sizes := ts.batchSizes("/api/v0/services/My-Service/tsdb")
+	if len(sizes) != 2 || sumInts(sizes) != 4 {
+		t.Errorf("values should be split into 2 batches but %v", sizes)
+	}
+	if stats := s.Stats(); stats.Sent != 4 || stats.Retried != 1 {
+		t.Errorf("Stats() should be Sent: 4 and Retried: 1 but %+v", stats)
+	}
+}
+
+func TestMetricSender_Failed(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write([]byte(`{"error":{"message":"invalid metric"}}`)) // nolint
+	}))
+	defer ts.Close()
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+	s := NewMetricSender(client, &MetricSenderOptions{FlushInterval: time.Hour})
+	defer s.Close(t.Context()) // nolint
+
+	s.AddHostMetricValues(t.Context(), &HostMetricValue{HostID: "9rxGOHfVF8F", MetricValue: &MetricValue{Name: "custom.metric"}}) // nolint
+	if err := s.Flush(t.Context()); !errors.Is(err, ErrValidation) {
+		t.Errorf("Flush should return the validation error but %v", err)
+	}
+	if stats := s.Stats(); stats.Failed != 1 || stats.Retried != 0 {
+		t.Errorf("Stats() should be Failed: 1 and Retried: 0 but %+v", stats)
+	}
+}
+
+func TestMetricSender_DropPolicy(t *testing.T) {
+	tests := []struct {
+		policy MetricDropPolicy
+		want   []int64
+	}{
+		{MetricDropPolicyDropNewest, []int64{0, 1}},
+		{MetricDropPolicyDropOldest, []int64{1, 2}},
+	}
+	for _, tt := range tests {
+		client := NewClient("dummy-key")
+		s := NewMetricSender(client, &MetricSenderOptions{BufferSize: 2, FlushInterval: time.Hour, DropPolicy: tt.policy})
+		for i := range 3 {
+			s.AddServiceMetricValues(t.Context(), "My-Service", &MetricValue{Time: int64(i)}) // nolint
+		}
+		var got []int64
+		for _, e := range s.take() {
+			got = append(got, e.value.Time)
+		}
+		if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+			t.Errorf("buffered values with policy %d should be %v but %v", tt.policy, tt.want, got)
+		}
+		if stats := s.Stats(); stats.Dropped != 1 {
+			t.Errorf("Stats().Dropped should be 1 but %+v", stats)
+		}
+		s.Close(t.Context()) // nolint
+	}
+}
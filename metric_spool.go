@@ -0,0 +1,259 @@
+package mackerel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mackerelio/mackerel-client-go/internal/fsutil"
+)
+
+const (
+	defaultMetricSpoolMaxBytes = 100 << 20
+	defaultMetricSpoolMaxAge   = 24 * time.Hour
+
+	metricSpoolFileExt = ".json"
+	// metricSpoolCorruptExt is appended to the names of batches that cannot be decoded.
+	metricSpoolCorruptExt = ".corrupt"
+)
+
+// MetricSpoolOptions configures [MetricSpool].
+// Zero-valued fields fall back to their defaults.
+type MetricSpoolOptions struct {
+	// MaxBytes is the maximum total size of the spooled files. The default is 100 MiB.
+	// The oldest batches are evicted when it is exceeded.
+	MaxBytes int64
+	// MaxAge is the maximum age of spooled batches, measured from their oldest value. The default is 24 hours.
+	MaxAge time.Duration
+}
+
+// MetricSpool is a durable queue of metric batches that failed to be posted.
+// Each batch is stored as a file in a directory and never modified after it is written.
+// It is safe for concurrent use, but a directory must not be shared between spools.
+type MetricSpool struct {
+	dir  string
+	opts MetricSpoolOptions
+	now  func() time.Time
+	seq  atomic.Uint64
+
+	mu       sync.Mutex
+	replayMu sync.Mutex
+}
+
+type metricSpoolBatch struct {
+	Service      string             `json:"service,omitempty"`
+	HostValues   []*HostMetricValue `json:"hostValues,omitempty"`
+	MetricValues []*MetricValue     `json:"metricValues,omitempty"`
+}
+
+// NewMetricSpool returns a new MetricSpool that stores batches in dir.
+// The directory is created if it does not exist.
+func NewMetricSpool(dir string, opts *MetricSpoolOptions) (*MetricSpool, error) {
+	var o MetricSpoolOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.MaxBytes <= 0 {
+		o.MaxBytes = defaultMetricSpoolMaxBytes
+	}
+	if o.MaxAge <= 0 {
+		o.MaxAge = defaultMetricSpoolMaxAge
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &MetricSpool{dir: dir, opts: o, now: time.Now}, nil
+}
+
+// SaveHostMetricValues stores host metric values to be posted later.
+func (s *MetricSpool) SaveHostMetricValues(values []*HostMetricValue) error {
+	if len(values) == 0 {
+		return nil
+	}
+	oldest := values[0].Time
+	for _, v := range values {
+		oldest = min(oldest, v.Time)
+	}
+	return s.save(oldest, &metricSpoolBatch{HostValues: values})
+}
+
+// SaveServiceMetricValues stores service metric values of serviceName to be posted later.
+func (s *MetricSpool) SaveServiceMetricValues(serviceName string, values []*MetricValue) error {
+	if len(values) == 0 {
+		return nil
+	}
+	oldest := values[0].Time
+	for _, v := range values {
+		oldest = min(oldest, v.Time)
+	}
+	return s.save(oldest, &metricSpoolBatch{Service: serviceName, MetricValues: values})
+}
+
+func (s *MetricSpool) save(oldest int64, batch *metricSpoolBatch) error {
+	// Negative timestamps would break the order of the file names.
+	if oldest < 0 {
+		return fmt.Errorf("invalid timestamp of metric values: %d", oldest)
+	}
+	b, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	// File names sort in the order of the oldest values of the batches.
+	name := fmt.Sprintf("%020d-%020d-%010d%s", oldest, s.now().UnixNano(), s.seq.Add(1), metricSpoolFileExt)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := fsutil.WriteFileAtomic(filepath.Join(s.dir, name), b); err != nil {
+		return err
+	}
+	return s.evict()
+}
+
+type metricSpoolFile struct {
+	name   string
+	size   int64
+	oldest time.Time
+}
+
+// files returns the spooled files in timestamp order.
+func (s *MetricSpool) files() ([]metricSpoolFile, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []metricSpoolFile
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || !strings.HasSuffix(name, metricSpoolFileExt) {
+			continue
+		}
+		ts, _, ok := strings.Cut(name, "-")
+		if !ok {
+			continue
+		}
+		oldest, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, metricSpoolFile{name: name, size: info.Size(), oldest: time.Unix(oldest, 0)})
+	}
+	slices.SortFunc(files, func(a, b metricSpoolFile) int { return strings.Compare(a.name, b.name) })
+	return files, nil
+}
+
+// evict removes batches that are too old or exceed MaxBytes. s.mu must be held.
+func (s *MetricSpool) evict() error {
+	files, err := s.files()
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	expiry := s.now().Add(-s.opts.MaxAge)
+	for _, f := range files {
+		if total <= s.opts.MaxBytes && !f.oldest.Before(expiry) {
+			break
+		}
+		if err := os.Remove(filepath.Join(s.dir, f.name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		total -= f.size
+	}
+	return nil
+}
+
+// Len returns the number of spooled batches.
+func (s *MetricSpool) Len() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files, err := s.files()
+	return len(files), err
+}
+
+// Replay posts the spooled batches in timestamp order via client and removes the posted ones.
+// It stops at the first batch that fails and returns the error, leaving the remaining batches for the next Replay.
+// Batches rejected by the API with a client error are discarded since they never succeed.
+// Batches that cannot be decoded are renamed with the suffix ".corrupt" and left in the directory.
+func (s *MetricSpool) Replay(ctx context.Context, client *Client) error {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	s.mu.Lock()
+	err := s.evict()
+	var files []metricSpoolFile
+	if err == nil {
+		files, err = s.files()
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		path := filepath.Join(s.dir, f.name)
+		b, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		var batch metricSpoolBatch
+		if err := json.Unmarshal(b, &batch); err != nil {
+			if err := s.setAside(path); err != nil {
+				return err
+			}
+			if l := client.slogger(); l != nil {
+				l.WarnContext(ctx, "set aside a corrupt batch of metrics", slog.String("path", path+metricSpoolCorruptExt), slog.String("error", err.Error()))
+			}
+			continue
+		}
+		if err := batch.post(ctx, client); err != nil && !isRejectedMetricError(err) {
+			return err
+		}
+		// The batch is removed before checking ctx so that it is never posted twice.
+		s.mu.Lock()
+		err = os.Remove(path)
+		s.mu.Unlock()
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MetricSpool) setAside(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Rename(path, path+metricSpoolCorruptExt); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (b *metricSpoolBatch) post(ctx context.Context, client *Client) error {
+	if b.Service != "" {
+		return client.PostServiceMetricValuesContext(ctx, b.Service, b.MetricValues)
+	}
+	return client.PostHostMetricValuesContext(ctx, b.HostValues)
+}
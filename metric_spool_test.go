@@ -0,0 +1,247 @@
+package mackerel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMetricSpool_Replay(t *testing.T) {
+	spool, err := NewMetricSpool(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	err = spool.SaveServiceMetricValues("My-Service", []*MetricValue{{Name: "custom.metric", Time: now - 60, Value: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = spool.SaveHostMetricValues([]*HostMetricValue{
+		{HostID: "9rxGOHfVF8F", MetricValue: &MetricValue{Name: "custom.metric", Time: now - 120, Value: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := spool.Len(); n != 2 {
+		t.Errorf("Len() should be 2 but %d", n)
+	}
+
+	var (
+		mu       sync.Mutex
+		paths    []string
+		unstable = true
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if unstable {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var values []map[string]any
+		if err := json.NewDecoder(req.Body).Decode(&values); err != nil {
+			t.Error(err)
+		}
+		if len(values) != 1 || values[0]["name"] != "custom.metric" {
+			t.Errorf("spooled values should be posted but %v", values)
+		}
+		paths = append(paths, req.URL.Path)
+		res.Write([]byte(`{"success":true}`)) // nolint
+	}))
+	defer ts.Close()
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+
+	if err := spool.Replay(t.Context(), client); err == nil {
+		t.Error("Replay should fail while the API is unavailable")
+	}
+	if n, _ := spool.Len(); n != 2 {
+		t.Errorf("Len() should be 2 after failed Replay but %d", n)
+	}
+
+	mu.Lock()
+	unstable = false
+	mu.Unlock()
+	if err := spool.Replay(t.Context(), client); err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || paths[0] != "/api/v0/tsdb" || paths[1] != "/api/v0/services/My-Service/tsdb" {
+		t.Errorf("batches should be posted in timestamp order but %v", paths)
+	}
+	if n, _ := spool.Len(); n != 0 {
+		t.Errorf("Len() should be 0 after Replay but %d", n)
+	}
+}
+
+func TestMetricSpool_Evict(t *testing.T) {
+	spool, err := NewMetricSpool(t.TempDir(), &MetricSpoolOptions{MaxAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	spool.SaveServiceMetricValues("My-Service", []*MetricValue{{Name: "old", Time: now - 7200}}) // nolint
+	spool.SaveServiceMetricValues("My-Service", []*MetricValue{{Name: "fresh", Time: now - 60}}) // nolint
+	if n, _ := spool.Len(); n != 1 {
+		t.Errorf("batches older than MaxAge should be evicted but Len() = %d", n)
+	}
+
+	spool.opts.MaxBytes = 1
+	spool.SaveServiceMetricValues("My-Service", []*MetricValue{{Name: "fresh", Time: now}}) // nolint
+	if n, _ := spool.Len(); n != 0 {
+		t.Errorf("batches exceeding MaxBytes should be evicted but Len() = %d", n)
+	}
+}
+
+func TestMetricSpool_ReplayKeepsUnsent(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := NewMetricSpool(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	spool.SaveServiceMetricValues("My-Service", []*MetricValue{{Name: "custom.metric", Time: now}}) // nolint
+	corrupt := filepath.Join(dir, fmt.Sprintf("%020d-0-0%s", now-60, metricSpoolFileExt))
+	if err := os.WriteFile(corrupt, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(`{"success":true}`)) // nolint
+	}))
+	defer ts.Close()
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if err := spool.Replay(ctx, client); !errors.Is(err, context.Canceled) {
+		t.Errorf("Replay should be canceled but: %v", err)
+	}
+	if n, _ := spool.Len(); n != 1 {
+		t.Errorf("batches should be kept when Replay is canceled but Len() = %d", n)
+	}
+	if _, err := os.Stat(corrupt + metricSpoolCorruptExt); err != nil {
+		t.Errorf("the corrupt batch should be set aside: %v", err)
+	}
+
+	if err := spool.Replay(t.Context(), client); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := spool.Len(); n != 0 {
+		t.Errorf("Len() should be 0 after Replay but %d", n)
+	}
+}
+
+// cancelingTransport responds successfully without sending requests and then cancels the context.
+type cancelingTransport struct {
+	cancel context.CancelFunc
+	n      int
+}
+
+func (t *cancelingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.n++
+	t.cancel()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(`{"success":true}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestMetricSpool_ReplayCanceledAfterPost(t *testing.T) {
+	spool, err := NewMetricSpool(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	spool.SaveServiceMetricValues("My-Service", []*MetricValue{{Name: "custom.metric", Time: now - 60}}) // nolint
+	spool.SaveServiceMetricValues("My-Service", []*MetricValue{{Name: "custom.metric", Time: now}})      // nolint
+
+	ctx, cancel := context.WithCancel(t.Context())
+	transport := &cancelingTransport{cancel: cancel}
+	client, _ := NewClientWithOptions("dummy-key", "http://localhost", false)
+	client.HTTPClient = &http.Client{Transport: transport}
+	if err := spool.Replay(ctx, client); !errors.Is(err, context.Canceled) {
+		t.Errorf("Replay should be canceled but: %v", err)
+	}
+	if n, _ := spool.Len(); transport.n != 1 || n != 1 {
+		t.Errorf("only the posted batch should be removed but %d posted and Len() = %d", transport.n, n)
+	}
+}
+
+func TestMetricSpool_NegativeTimestamp(t *testing.T) {
+	spool, err := NewMetricSpool(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := spool.SaveServiceMetricValues("My-Service", []*MetricValue{{Name: "custom.metric", Time: -1}}); err == nil {
+		t.Error("negative timestamps should be rejected")
+	}
+	if n, _ := spool.Len(); n != 0 {
+		t.Errorf("nothing should be spooled but Len() = %d", n)
+	}
+}
+
+func TestMetricSender_Spool(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		down   = true
+		posted int
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if down {
+			res.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var values []json.RawMessage
+		json.NewDecoder(req.Body).Decode(&values) // nolint
+		posted += len(values)
+		res.Write([]byte(`{"success":true}`)) // nolint
+	}))
+	defer ts.Close()
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+	spool, err := NewMetricSpool(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewMetricSender(client, &MetricSenderOptions{
+		FlushInterval: time.Hour,
+		RetryPolicy:   &RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond},
+		Spool:         spool,
+	})
+	defer s.Close(t.Context()) // nolint
+
+	now := time.Now().Unix()
+	s.AddServiceMetricValues(t.Context(), "My-Service", &MetricValue{Name: "custom.metric", Time: now}) // nolint
+	if err := s.Flush(t.Context()); err == nil {
+		t.Error("Flush should report that the spooled values could not be replayed")
+	}
+	if stats := s.Stats(); stats.Spooled != 1 || stats.Failed != 0 {
+		t.Errorf("Stats() should be Spooled: 1 and Failed: 0 but %+v", stats)
+	}
+
+	mu.Lock()
+	down = false
+	mu.Unlock()
+	s.AddServiceMetricValues(t.Context(), "My-Service", &MetricValue{Name: "custom.metric", Time: now + 60}) // nolint
+	if err := s.Flush(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if posted != 2 {
+		t.Errorf("both the new and spooled values should be posted but %d", posted)
+	}
+	if n, _ := spool.Len(); n != 0 {
+		t.Errorf("spool should be empty but Len() = %d", n)
+	}
+}
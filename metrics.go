@@ -31,6 +31,7 @@ func (c *Client) PostHostMetricValues(metricValues []*HostMetricValue) error {
 
 // PostHostMetricValuesContext post host metrics
 func (c *Client) PostHostMetricValuesContext(ctx context.Context, metricValues []*HostMetricValue) error {
+	ctx = withOperationName(ctx, "PostHostMetricValues")
 	_, err := requestPostContext[any](ctx, c, "/api/v0/tsdb", metricValues)
 	return err
 }
@@ -59,6 +60,7 @@ func (c *Client) PostServiceMetricValues(serviceName string, metricValues []*Met
 
 // PostServiceMetricValuesContext posts service metrics.
 func (c *Client) PostServiceMetricValuesContext(ctx context.Context, serviceName string, metricValues []*MetricValue) error {
+	ctx = withOperationName(ctx, "PostServiceMetricValues")
 	path := fmt.Sprintf("/api/v0/services/%s/tsdb", serviceName)
 	_, err := requestPostContext[any](ctx, c, path, metricValues)
 	return err
@@ -71,6 +73,7 @@ func (c *Client) FetchLatestMetricValues(hostIDs []string, metricNames []string)
 
 // FetchLatestMetricValuesContext fetches latest metrics.
 func (c *Client) FetchLatestMetricValuesContext(ctx context.Context, hostIDs []string, metricNames []string) (LatestMetricValues, error) {
+	ctx = withOperationName(ctx, "FetchLatestMetricValues")
 	params := url.Values{}
 	for _, hostID := range hostIDs {
 		params.Add("hostId", hostID)
@@ -90,21 +93,23 @@ func (c *Client) FetchLatestMetricValuesContext(ctx context.Context, hostIDs []s
 
 // FetchHostMetricValues fetches the metric values for a host.
 func (c *Client) FetchHostMetricValues(hostID string, metricName string, from int64, to int64) ([]MetricValue, error) {
-	return c.fetchMetricValues(context.Background(), hostID, "", metricName, from, to)
+	return c.FetchHostMetricValuesContext(context.Background(), hostID, metricName, from, to)
 }
 
 // FetchHostMetricValuesContext fetches the metric values for a host.
 func (c *Client) FetchHostMetricValuesContext(ctx context.Context, hostID string, metricName string, from int64, to int64) ([]MetricValue, error) {
+	ctx = withOperationName(ctx, "FetchHostMetricValues")
 	return c.fetchMetricValues(ctx, hostID, "", metricName, from, to)
 }
 
 // FetchServiceMetricValues fetches the metric values for a service.
 func (c *Client) FetchServiceMetricValues(serviceName string, metricName string, from int64, to int64) ([]MetricValue, error) {
-	return c.fetchMetricValues(context.Background(), "", serviceName, metricName, from, to)
+	return c.FetchServiceMetricValuesContext(context.Background(), serviceName, metricName, from, to)
 }
 
 // FetchServiceMetricValuesContext fetches the metric values for a service.
 func (c *Client) FetchServiceMetricValuesContext(ctx context.Context, serviceName string, metricName string, from int64, to int64) ([]MetricValue, error) {
+	ctx = withOperationName(ctx, "FetchServiceMetricValues")
 	return c.fetchMetricValues(ctx, "", serviceName, metricName, from, to)
 }
 
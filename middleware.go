@@ -0,0 +1,70 @@
+package mackerel
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Operation describes a logical API call that a request is sent for.
+type Operation struct {
+	// Name is the name of the Client method without the Context suffix, such as "FindHosts".
+	// It is "Request" for requests sent by [Client.Request] directly.
+	// The requests of iterators such as AlertLogsSeq are named after the methods fetching the pages, such as "FindAlertLogs".
+	Name string
+	// Method and Path are the HTTP method and the path of the request.
+	Method string
+	Path   string
+	// Query is the query parameters of the request.
+	Query url.Values
+	// Payload is the value encoded to the request body, such as *CreateHostParam.
+	// It is nil for requests without body or sent by [Client.Request] directly.
+	Payload any
+	// Attempts is the number of attempts made to send the request, including retries.
+	// It is set after the innermost handler returns.
+	Attempts int
+}
+
+// Handler sends a request for op and returns the response.
+// Responses of non-2xx status codes are returned as *APIError.
+type Handler func(op *Operation, req *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler to add behavior to sending requests.
+// req has all the headers set by Client, including X-Api-Key.
+// A middleware may modify req, return its own response without calling next, or inspect the result of next.
+// Retries and rate limiting happen inside the innermost handler, so next is called once per operation.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware appends middlewares of the client. The first one is the outermost.
+func WithMiddleware(m ...Middleware) Option {
+	return func(o *clientOptions) error {
+		o.middlewares = append(o.middlewares, m...)
+		return nil
+	}
+}
+
+type operationNameKey struct{}
+
+// withOperationName returns ctx that names the operations of the requests sent with it.
+// Each exported Client method sets its own name, so the innermost one is used for nested calls.
+func withOperationName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationNameKey{}, name)
+}
+
+// operationName returns the name set by withOperationName, or "Request" for requests sent with the other contexts.
+func operationName(ctx context.Context) string {
+	if name, ok := ctx.Value(operationNameKey{}).(string); ok {
+		return name
+	}
+	return "Request"
+}
+
+func newOperation(name, method, path string, query url.Values, payload any) *Operation {
+	return &Operation{
+		Name:    name,
+		Method:  method,
+		Path:    path,
+		Query:   query,
+		Payload: payload,
+	}
+}
@@ -0,0 +1,165 @@
+package mackerel
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	var failures int
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Audit") != "on" {
+			t.Error("X-Audit header should be added by middleware")
+		}
+		if failures > 0 {
+			failures--
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch req.URL.Path {
+		case "/api/v0/hosts":
+			res.Write([]byte(`{"id":"9rxGOHfVF8F"}`)) // nolint
+		default:
+			res.Write([]byte(`{"hosts":[]}`)) // nolint
+		}
+	}))
+	defer ts.Close()
+
+	var ops []Operation
+	audit := func(next Handler) Handler {
+		return func(op *Operation, req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Api-Key") != "dummy-key" {
+				t.Error("X-Api-Key header should be set before middleware")
+			}
+			req.Header.Set("X-Audit", "on")
+			resp, err := next(op, req)
+			ops = append(ops, *op)
+			return resp, err
+		}
+	}
+	client, err := New("dummy-key", WithBaseURL(ts.URL), WithMiddleware(audit),
+		WithRetryPolicy(&RetryPolicy{BaseBackoff: time.Millisecond, RetryNonIdempotent: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failures = 1
+	param := &CreateHostParam{Name: "web01"}
+	if _, err := client.CreateHost(param); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.FindHostsContext(t.Context(), &FindHostsParam{Service: "My-Service"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 2 {
+		t.Fatal("middleware should be called once per operation but: ", len(ops))
+	}
+	if ops[0].Name != "CreateHost" || ops[0].Method != http.MethodPost || ops[0].Path != "/api/v0/hosts" {
+		t.Errorf("operation should be CreateHost but: %+v", ops[0])
+	}
+	if ops[0].Payload != param {
+		t.Errorf("payload should be the param but: %+v", ops[0].Payload)
+	}
+	if ops[0].Attempts != 2 {
+		t.Errorf("operation should be attempted twice but: %d", ops[0].Attempts)
+	}
+	if ops[1].Name != "FindHosts" || ops[1].Query.Get("service") != "My-Service" || ops[1].Payload != nil {
+		t.Errorf("operation should be FindHosts but: %+v", ops[1])
+	}
+}
+
+func TestMiddleware_ShortCircuit(t *testing.T) {
+	cache := func(next Handler) Handler {
+		return func(op *Operation, req *http.Request) (*http.Response, error) {
+			if op.Name != "GetOrg" {
+				return next(op, req)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{"name":"cached-org"}`))),
+				Request:    req,
+			}, nil
+		}
+	}
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(op *Operation, req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next(op, req)
+			}
+		}
+	}
+	client, err := New("dummy-key", WithBaseURL("http://127.0.0.1:0"), WithMiddleware(trace("outer"), trace("inner"), cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	org, err := client.GetOrgContext(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if org.Name != "cached-org" {
+		t.Error("org should be returned by middleware but: ", org.Name)
+	}
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Error("middlewares should be called from the first one but: ", order)
+	}
+}
+
+func TestOperationName(t *testing.T) {
+	var names []string
+	client, _ := New("dummy-key", WithBaseURL("http://127.0.0.1:0"), WithMiddleware(func(next Handler) Handler {
+		return func(op *Operation, req *http.Request) (*http.Response, error) {
+			names = append(names, op.Name)
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte(`{}`)))}, nil
+		}
+	}))
+	client.FindAlerts()                                   // nolint
+	client.PostJSON("/api/v0/custom", nil)                // nolint
+	for range client.AlertsSeq(t.Context(), false, nil) { // nolint
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1:0/api/v0/org", nil)
+	client.Request(req)                                    // nolint
+	client.DeleteRole("My-Service", "db")                  // nolint
+	client.QueryHosts(&QueryHostsParam{})                  // nolint
+	client.FetchHostMetricValues("a", "m", 0, 1)           // nolint
+	for range client.AlertLogsSeq(t.Context(), "a", nil) { // nolint
+	}
+	for range client.CheckMonitorsSeq(t.Context(), nil) { // nolint
+	}
+
+	want := []string{"FindAlerts", "PostJSON", "FindAlerts", "Request", "DeleteRole", "FindHosts", "FetchHostMetricValues", "FindAlertLogs", "FindCheckMonitors"}
+	if len(names) != len(want) {
+		t.Fatalf("operation names should be %v but: %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("operation names should be %v but: %v", want, names)
+		}
+	}
+}
+
+func TestOperation_Route(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v0/hosts", "/api/v0/hosts"},
+		{"/api/v0/hosts/9rxGOHfVF8F", "/api/v0/hosts/{hostId}"},
+		{"/api/v0/hosts/bulk-retire", "/api/v0/hosts/bulk-retire"},
+		{"/api/v0/hosts-by-custom-identifier/i-0123%2F456", "/api/v0/hosts-by-custom-identifier/{customIdentifier}"},
+		{"/api/v0/services/My-Service/roles/proxy/metadata/ns", "/api/v0/services/{serviceName}/roles/{roleName}/metadata/{namespace}"},
+		{"/api/v0/unknown", ""},
+	}
+	for _, tt := range tests {
+		op := &Operation{Method: http.MethodGet, Path: tt.path}
+		if got := op.Route(); got != tt.want {
+			t.Errorf("Route() of %s should be %q but: %q", tt.path, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,552 @@
+package mackerel
+
+import "slices"
+
+// ConnectivityMonitorBuilder builds a [MonitorConnectivity].
+type ConnectivityMonitorBuilder struct {
+	m MonitorConnectivity
+}
+
+// NewConnectivityMonitorBuilder returns a builder of a connectivity monitor named name.
+func NewConnectivityMonitorBuilder(name string) *ConnectivityMonitorBuilder {
+	return &ConnectivityMonitorBuilder{m: MonitorConnectivity{Type: monitorTypeConnectivity, Name: name}}
+}
+
+// Memo sets the memo of the monitor.
+func (b *ConnectivityMonitorBuilder) Memo(memo string) *ConnectivityMonitorBuilder {
+	b.m.Memo = memo
+	return b
+}
+
+// Mute sets whether the monitor is muted.
+func (b *ConnectivityMonitorBuilder) Mute(mute bool) *ConnectivityMonitorBuilder {
+	b.m.IsMute = mute
+	return b
+}
+
+// NotificationInterval sets the interval of re-sending notifications in minutes.
+func (b *ConnectivityMonitorBuilder) NotificationInterval(minutes uint64) *ConnectivityMonitorBuilder {
+	b.m.NotificationInterval = minutes
+	return b
+}
+
+// Scopes appends the services or roles the monitor targets.
+func (b *ConnectivityMonitorBuilder) Scopes(scopes ...string) *ConnectivityMonitorBuilder {
+	b.m.Scopes = append(b.m.Scopes, scopes...)
+	return b
+}
+
+// ExcludeScopes appends the services or roles the monitor excludes.
+func (b *ConnectivityMonitorBuilder) ExcludeScopes(scopes ...string) *ConnectivityMonitorBuilder {
+	b.m.ExcludeScopes = append(b.m.ExcludeScopes, scopes...)
+	return b
+}
+
+// AlertStatusOnGone sets the status of alerts when hosts are gone, "CRITICAL" or "WARNING".
+func (b *ConnectivityMonitorBuilder) AlertStatusOnGone(status string) *ConnectivityMonitorBuilder {
+	b.m.AlertStatusOnGone = status
+	return b
+}
+
+// Build validates and returns a copy of the monitor, so the builder can be reused to build another one.
+func (b *ConnectivityMonitorBuilder) Build() (*MonitorConnectivity, error) {
+	m := b.m
+	m.Scopes = slices.Clone(m.Scopes)
+	m.ExcludeScopes = slices.Clone(m.ExcludeScopes)
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// HostMetricMonitorBuilder builds a [MonitorHostMetric].
+type HostMetricMonitorBuilder struct {
+	m MonitorHostMetric
+}
+
+// NewHostMetricMonitorBuilder returns a builder of a host metric monitor named name that watches metric.
+func NewHostMetricMonitorBuilder(name, metric string) *HostMetricMonitorBuilder {
+	return &HostMetricMonitorBuilder{m: MonitorHostMetric{Type: monitorTypeHostMetric, Name: name, Metric: metric}}
+}
+
+// Memo sets the memo of the monitor.
+func (b *HostMetricMonitorBuilder) Memo(memo string) *HostMetricMonitorBuilder {
+	b.m.Memo = memo
+	return b
+}
+
+// Mute sets whether the monitor is muted.
+func (b *HostMetricMonitorBuilder) Mute(mute bool) *HostMetricMonitorBuilder {
+	b.m.IsMute = mute
+	return b
+}
+
+// NotificationInterval sets the interval of re-sending notifications in minutes.
+func (b *HostMetricMonitorBuilder) NotificationInterval(minutes uint64) *HostMetricMonitorBuilder {
+	b.m.NotificationInterval = minutes
+	return b
+}
+
+// Operator sets the operator comparing the metric with the thresholds, [MonitorOperatorGreater] or [MonitorOperatorLess].
+func (b *HostMetricMonitorBuilder) Operator(operator string) *HostMetricMonitorBuilder {
+	b.m.Operator = operator
+	return b
+}
+
+// Warning sets the warning threshold.
+func (b *HostMetricMonitorBuilder) Warning(v float64) *HostMetricMonitorBuilder {
+	b.m.Warning = &v
+	return b
+}
+
+// Critical sets the critical threshold.
+func (b *HostMetricMonitorBuilder) Critical(v float64) *HostMetricMonitorBuilder {
+	b.m.Critical = &v
+	return b
+}
+
+// Duration sets the number of minutes the metric is averaged over.
+func (b *HostMetricMonitorBuilder) Duration(minutes uint64) *HostMetricMonitorBuilder {
+	b.m.Duration = minutes
+	return b
+}
+
+// MaxCheckAttempts sets the number of consecutive failures to alert.
+func (b *HostMetricMonitorBuilder) MaxCheckAttempts(n uint64) *HostMetricMonitorBuilder {
+	b.m.MaxCheckAttempts = n
+	return b
+}
+
+// Scopes appends the services or roles the monitor targets.
+func (b *HostMetricMonitorBuilder) Scopes(scopes ...string) *HostMetricMonitorBuilder {
+	b.m.Scopes = append(b.m.Scopes, scopes...)
+	return b
+}
+
+// ExcludeScopes appends the services or roles the monitor excludes.
+func (b *HostMetricMonitorBuilder) ExcludeScopes(scopes ...string) *HostMetricMonitorBuilder {
+	b.m.ExcludeScopes = append(b.m.ExcludeScopes, scopes...)
+	return b
+}
+
+// Build validates and returns a copy of the monitor, so the builder can be reused to build another one.
+func (b *HostMetricMonitorBuilder) Build() (*MonitorHostMetric, error) {
+	m := b.m
+	m.Scopes = slices.Clone(m.Scopes)
+	m.ExcludeScopes = slices.Clone(m.ExcludeScopes)
+	m.Warning = clonePointer(m.Warning)
+	m.Critical = clonePointer(m.Critical)
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ServiceMetricMonitorBuilder builds a [MonitorServiceMetric].
+type ServiceMetricMonitorBuilder struct {
+	m MonitorServiceMetric
+}
+
+// NewServiceMetricMonitorBuilder returns a builder of a service metric monitor named name that watches metric of service.
+func NewServiceMetricMonitorBuilder(name, service, metric string) *ServiceMetricMonitorBuilder {
+	return &ServiceMetricMonitorBuilder{m: MonitorServiceMetric{
+		Type:    monitorTypeServiceMetric,
+		Name:    name,
+		Service: service,
+		Metric:  metric,
+	}}
+}
+
+// Memo sets the memo of the monitor.
+func (b *ServiceMetricMonitorBuilder) Memo(memo string) *ServiceMetricMonitorBuilder {
+	b.m.Memo = memo
+	return b
+}
+
+// Mute sets whether the monitor is muted.
+func (b *ServiceMetricMonitorBuilder) Mute(mute bool) *ServiceMetricMonitorBuilder {
+	b.m.IsMute = mute
+	return b
+}
+
+// NotificationInterval sets the interval of re-sending notifications in minutes.
+func (b *ServiceMetricMonitorBuilder) NotificationInterval(minutes uint64) *ServiceMetricMonitorBuilder {
+	b.m.NotificationInterval = minutes
+	return b
+}
+
+// Operator sets the operator comparing the metric with the thresholds, [MonitorOperatorGreater] or [MonitorOperatorLess].
+func (b *ServiceMetricMonitorBuilder) Operator(operator string) *ServiceMetricMonitorBuilder {
+	b.m.Operator = operator
+	return b
+}
+
+// Warning sets the warning threshold.
+func (b *ServiceMetricMonitorBuilder) Warning(v float64) *ServiceMetricMonitorBuilder {
+	b.m.Warning = &v
+	return b
+}
+
+// Critical sets the critical threshold.
+func (b *ServiceMetricMonitorBuilder) Critical(v float64) *ServiceMetricMonitorBuilder {
+	b.m.Critical = &v
+	return b
+}
+
+// Duration sets the number of points the metric is averaged over.
+func (b *ServiceMetricMonitorBuilder) Duration(points uint64) *ServiceMetricMonitorBuilder {
+	b.m.Duration = points
+	return b
+}
+
+// MaxCheckAttempts sets the number of consecutive failures to alert.
+func (b *ServiceMetricMonitorBuilder) MaxCheckAttempts(n uint64) *ServiceMetricMonitorBuilder {
+	b.m.MaxCheckAttempts = n
+	return b
+}
+
+// MissingDuration sets the minutes without posted metrics to alert warning and critical.
+// Zero disables the alert of the level.
+func (b *ServiceMetricMonitorBuilder) MissingDuration(warning, critical uint64) *ServiceMetricMonitorBuilder {
+	b.m.MissingDurationWarning = warning
+	b.m.MissingDurationCritical = critical
+	return b
+}
+
+// Build validates and returns a copy of the monitor, so the builder can be reused to build another one.
+func (b *ServiceMetricMonitorBuilder) Build() (*MonitorServiceMetric, error) {
+	m := b.m
+	m.Warning = clonePointer(m.Warning)
+	m.Critical = clonePointer(m.Critical)
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ExternalHTTPMonitorBuilder builds a [MonitorExternalHTTP].
+type ExternalHTTPMonitorBuilder struct {
+	m MonitorExternalHTTP
+}
+
+// NewExternalHTTPMonitorBuilder returns a builder of an external HTTP monitor named name that requests url with GET.
+func NewExternalHTTPMonitorBuilder(name, url string) *ExternalHTTPMonitorBuilder {
+	return &ExternalHTTPMonitorBuilder{m: MonitorExternalHTTP{
+		Type:   monitorTypeExternalHTTP,
+		Name:   name,
+		Method: "GET",
+		URL:    url,
+	}}
+}
+
+// Memo sets the memo of the monitor.
+func (b *ExternalHTTPMonitorBuilder) Memo(memo string) *ExternalHTTPMonitorBuilder {
+	b.m.Memo = memo
+	return b
+}
+
+// Mute sets whether the monitor is muted.
+func (b *ExternalHTTPMonitorBuilder) Mute(mute bool) *ExternalHTTPMonitorBuilder {
+	b.m.IsMute = mute
+	return b
+}
+
+// NotificationInterval sets the interval of re-sending notifications in minutes.
+func (b *ExternalHTTPMonitorBuilder) NotificationInterval(minutes uint64) *ExternalHTTPMonitorBuilder {
+	b.m.NotificationInterval = minutes
+	return b
+}
+
+// Method sets the HTTP method of the request, one of GET, POST, PUT and DELETE.
+func (b *ExternalHTTPMonitorBuilder) Method(method string) *ExternalHTTPMonitorBuilder {
+	b.m.Method = method
+	return b
+}
+
+// RequestBody sets the body of the request.
+func (b *ExternalHTTPMonitorBuilder) RequestBody(body string) *ExternalHTTPMonitorBuilder {
+	b.m.RequestBody = body
+	return b
+}
+
+// Header appends a header of the request.
+func (b *ExternalHTTPMonitorBuilder) Header(name, value string) *ExternalHTTPMonitorBuilder {
+	b.m.Headers = append(b.m.Headers, HeaderField{Name: name, Value: value})
+	return b
+}
+
+// Service sets the service whose metrics the response time is posted to.
+func (b *ExternalHTTPMonitorBuilder) Service(service string) *ExternalHTTPMonitorBuilder {
+	b.m.Service = service
+	return b
+}
+
+// ResponseTime sets the thresholds of the response time in milliseconds averaged over duration minutes.
+func (b *ExternalHTTPMonitorBuilder) ResponseTime(warning, critical float64, duration uint64) *ExternalHTTPMonitorBuilder {
+	b.m.ResponseTimeWarning = &warning
+	b.m.ResponseTimeCritical = &critical
+	b.m.ResponseTimeDuration = &duration
+	return b
+}
+
+// CertificationExpiration sets the thresholds of the days until the certificate expires.
+func (b *ExternalHTTPMonitorBuilder) CertificationExpiration(warning, critical uint64) *ExternalHTTPMonitorBuilder {
+	b.m.CertificationExpirationWarning = &warning
+	b.m.CertificationExpirationCritical = &critical
+	return b
+}
+
+// ExpectedStatusCode sets the status code of successful responses.
+func (b *ExternalHTTPMonitorBuilder) ExpectedStatusCode(code int) *ExternalHTTPMonitorBuilder {
+	b.m.ExpectedStatusCode = &code
+	return b
+}
+
+// ContainsString sets the string successful responses contain.
+func (b *ExternalHTTPMonitorBuilder) ContainsString(s string) *ExternalHTTPMonitorBuilder {
+	b.m.ContainsString = s
+	return b
+}
+
+// SkipCertificateVerification sets whether the certificate is not verified.
+func (b *ExternalHTTPMonitorBuilder) SkipCertificateVerification(skip bool) *ExternalHTTPMonitorBuilder {
+	b.m.SkipCertificateVerification = skip
+	return b
+}
+
+// FollowRedirect sets whether redirects are followed.
+func (b *ExternalHTTPMonitorBuilder) FollowRedirect(follow bool) *ExternalHTTPMonitorBuilder {
+	b.m.FollowRedirect = follow
+	return b
+}
+
+// MaxCheckAttempts sets the number of consecutive failures to alert.
+func (b *ExternalHTTPMonitorBuilder) MaxCheckAttempts(n uint64) *ExternalHTTPMonitorBuilder {
+	b.m.MaxCheckAttempts = n
+	return b
+}
+
+// Build validates and returns a copy of the monitor, so the builder can be reused to build another one.
+func (b *ExternalHTTPMonitorBuilder) Build() (*MonitorExternalHTTP, error) {
+	m := b.m
+	m.Headers = slices.Clone(m.Headers)
+	m.ResponseTimeWarning = clonePointer(m.ResponseTimeWarning)
+	m.ResponseTimeCritical = clonePointer(m.ResponseTimeCritical)
+	m.ResponseTimeDuration = clonePointer(m.ResponseTimeDuration)
+	m.CertificationExpirationWarning = clonePointer(m.CertificationExpirationWarning)
+	m.CertificationExpirationCritical = clonePointer(m.CertificationExpirationCritical)
+	m.ExpectedStatusCode = clonePointer(m.ExpectedStatusCode)
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ExpressionMonitorBuilder builds a [MonitorExpression].
+type ExpressionMonitorBuilder struct {
+	m MonitorExpression
+}
+
+// NewExpressionMonitorBuilder returns a builder of an expression monitor named name that evaluates expression.
+func NewExpressionMonitorBuilder(name, expression string) *ExpressionMonitorBuilder {
+	return &ExpressionMonitorBuilder{m: MonitorExpression{Type: monitorTypeExpression, Name: name, Expression: expression}}
+}
+
+// Memo sets the memo of the monitor.
+func (b *ExpressionMonitorBuilder) Memo(memo string) *ExpressionMonitorBuilder {
+	b.m.Memo = memo
+	return b
+}
+
+// Mute sets whether the monitor is muted.
+func (b *ExpressionMonitorBuilder) Mute(mute bool) *ExpressionMonitorBuilder {
+	b.m.IsMute = mute
+	return b
+}
+
+// NotificationInterval sets the interval of re-sending notifications in minutes.
+func (b *ExpressionMonitorBuilder) NotificationInterval(minutes uint64) *ExpressionMonitorBuilder {
+	b.m.NotificationInterval = minutes
+	return b
+}
+
+// Operator sets the operator comparing the value with the thresholds, [MonitorOperatorGreater] or [MonitorOperatorLess].
+func (b *ExpressionMonitorBuilder) Operator(operator string) *ExpressionMonitorBuilder {
+	b.m.Operator = operator
+	return b
+}
+
+// Warning sets the warning threshold.
+func (b *ExpressionMonitorBuilder) Warning(v float64) *ExpressionMonitorBuilder {
+	b.m.Warning = &v
+	return b
+}
+
+// Critical sets the critical threshold.
+func (b *ExpressionMonitorBuilder) Critical(v float64) *ExpressionMonitorBuilder {
+	b.m.Critical = &v
+	return b
+}
+
+// EvaluateBackwardMinutes sets the minutes of the values evaluated.
+func (b *ExpressionMonitorBuilder) EvaluateBackwardMinutes(minutes uint64) *ExpressionMonitorBuilder {
+	b.m.EvaluateBackwardMinutes = &minutes
+	return b
+}
+
+// Build validates and returns a copy of the monitor, so the builder can be reused to build another one.
+func (b *ExpressionMonitorBuilder) Build() (*MonitorExpression, error) {
+	m := b.m
+	m.Warning = clonePointer(m.Warning)
+	m.Critical = clonePointer(m.Critical)
+	m.EvaluateBackwardMinutes = clonePointer(m.EvaluateBackwardMinutes)
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// AnomalyDetectionMonitorBuilder builds a [MonitorAnomalyDetection].
+type AnomalyDetectionMonitorBuilder struct {
+	m MonitorAnomalyDetection
+}
+
+// NewAnomalyDetectionMonitorBuilder returns a builder of an anomaly detection monitor named name that targets scopes.
+func NewAnomalyDetectionMonitorBuilder(name string, scopes ...string) *AnomalyDetectionMonitorBuilder {
+	return &AnomalyDetectionMonitorBuilder{m: MonitorAnomalyDetection{
+		Type:   monitorTypeAnomalyDetection,
+		Name:   name,
+		Scopes: slices.Clone(scopes),
+	}}
+}
+
+// Memo sets the memo of the monitor.
+func (b *AnomalyDetectionMonitorBuilder) Memo(memo string) *AnomalyDetectionMonitorBuilder {
+	b.m.Memo = memo
+	return b
+}
+
+// Mute sets whether the monitor is muted.
+func (b *AnomalyDetectionMonitorBuilder) Mute(mute bool) *AnomalyDetectionMonitorBuilder {
+	b.m.IsMute = mute
+	return b
+}
+
+// NotificationInterval sets the interval of re-sending notifications in minutes.
+func (b *AnomalyDetectionMonitorBuilder) NotificationInterval(minutes uint64) *AnomalyDetectionMonitorBuilder {
+	b.m.NotificationInterval = minutes
+	return b
+}
+
+// WarningSensitivity sets the sensitivity to alert warning, such as [SensitivityNormal].
+func (b *AnomalyDetectionMonitorBuilder) WarningSensitivity(sensitivity string) *AnomalyDetectionMonitorBuilder {
+	b.m.WarningSensitivity = sensitivity
+	return b
+}
+
+// CriticalSensitivity sets the sensitivity to alert critical, such as [SensitivityInsensitive].
+func (b *AnomalyDetectionMonitorBuilder) CriticalSensitivity(sensitivity string) *AnomalyDetectionMonitorBuilder {
+	b.m.CriticalSensitivity = sensitivity
+	return b
+}
+
+// TrainingPeriodFrom sets the epoch seconds from which the metrics are used for training.
+func (b *AnomalyDetectionMonitorBuilder) TrainingPeriodFrom(epoch uint64) *AnomalyDetectionMonitorBuilder {
+	b.m.TrainingPeriodFrom = epoch
+	return b
+}
+
+// MaxCheckAttempts sets the number of consecutive failures to alert.
+func (b *AnomalyDetectionMonitorBuilder) MaxCheckAttempts(n uint64) *AnomalyDetectionMonitorBuilder {
+	b.m.MaxCheckAttempts = n
+	return b
+}
+
+// Build validates and returns a copy of the monitor, so the builder can be reused to build another one.
+func (b *AnomalyDetectionMonitorBuilder) Build() (*MonitorAnomalyDetection, error) {
+	m := b.m
+	m.Scopes = slices.Clone(m.Scopes)
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// QueryMonitorBuilder builds a [MonitorQuery].
+type QueryMonitorBuilder struct {
+	m MonitorQuery
+}
+
+// NewQueryMonitorBuilder returns a builder of a query monitor named name that evaluates query.
+func NewQueryMonitorBuilder(name, query string) *QueryMonitorBuilder {
+	return &QueryMonitorBuilder{m: MonitorQuery{Type: monitorTypeQuery, Name: name, Query: query}}
+}
+
+// Memo sets the memo of the monitor.
+func (b *QueryMonitorBuilder) Memo(memo string) *QueryMonitorBuilder {
+	b.m.Memo = memo
+	return b
+}
+
+// Mute sets whether the monitor is muted.
+func (b *QueryMonitorBuilder) Mute(mute bool) *QueryMonitorBuilder {
+	b.m.IsMute = mute
+	return b
+}
+
+// NotificationInterval sets the interval of re-sending notifications in minutes.
+func (b *QueryMonitorBuilder) NotificationInterval(minutes uint64) *QueryMonitorBuilder {
+	b.m.NotificationInterval = minutes
+	return b
+}
+
+// Operator sets the operator comparing the values with the thresholds, [MonitorOperatorGreater] or [MonitorOperatorLess].
+func (b *QueryMonitorBuilder) Operator(operator string) *QueryMonitorBuilder {
+	b.m.Operator = operator
+	return b
+}
+
+// Warning sets the warning threshold.
+func (b *QueryMonitorBuilder) Warning(v float64) *QueryMonitorBuilder {
+	b.m.Warning = &v
+	return b
+}
+
+// Critical sets the critical threshold.
+func (b *QueryMonitorBuilder) Critical(v float64) *QueryMonitorBuilder {
+	b.m.Critical = &v
+	return b
+}
+
+// Legend sets the legend of the series.
+func (b *QueryMonitorBuilder) Legend(legend string) *QueryMonitorBuilder {
+	b.m.Legend = legend
+	return b
+}
+
+// EvaluateBackwardMinutes sets the minutes of the values evaluated.
+func (b *QueryMonitorBuilder) EvaluateBackwardMinutes(minutes uint64) *QueryMonitorBuilder {
+	b.m.EvaluateBackwardMinutes = &minutes
+	return b
+}
+
+// Build validates and returns a copy of the monitor, so the builder can be reused to build another one.
+func (b *QueryMonitorBuilder) Build() (*MonitorQuery, error) {
+	m := b.m
+	m.Warning = clonePointer(m.Warning)
+	m.Critical = clonePointer(m.Critical)
+	m.EvaluateBackwardMinutes = clonePointer(m.EvaluateBackwardMinutes)
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// clonePointer returns a pointer to a copy of the value p points to, or nil if p is nil.
+func clonePointer[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
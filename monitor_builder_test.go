@@ -0,0 +1,154 @@
+package mackerel
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestMonitorBuilder(t *testing.T) {
+	m, err := NewHostMetricMonitorBuilder("loadavg5", "loadavg5").
+		Operator(MonitorOperatorGreater).Warning(4).Critical(8).Duration(3).
+		Scopes("My-Service").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Type != "host" || *m.Warning != 4 || *m.Critical != 8 || m.Duration != 3 || !slices.Equal(m.Scopes, []string{"My-Service"}) {
+		t.Errorf("unexpected monitor: %+v", m)
+	}
+
+	_, err = NewExternalHTTPMonitorBuilder("example.com", "https://example.com/").
+		ResponseTime(10000, 5000, 5).
+		Build()
+	if !errors.Is(err, ErrValidation) {
+		t.Error("Build should fail with ErrValidation but: ", err)
+	}
+
+	a, err := NewAnomalyDetectionMonitorBuilder("anomaly", "My-Service: db").
+		WarningSensitivity(SensitivityNormal).CriticalSensitivity(SensitivityInsensitive).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Type != "anomalyDetection" || a.WarningSensitivity != "normal" {
+		t.Errorf("unexpected monitor: %+v", a)
+	}
+}
+
+func TestMonitorBuilder_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func() (Monitor, error)
+		field string
+	}{
+		{
+			name: "connectivity",
+			build: func() (Monitor, error) {
+				return NewConnectivityMonitorBuilder("connectivity").AlertStatusOnGone("OK").Build()
+			},
+			field: "alertStatusOnGone",
+		},
+		{
+			name: "host metric",
+			build: func() (Monitor, error) {
+				return NewHostMetricMonitorBuilder("loadavg5", "loadavg5").Operator(MonitorOperatorGreater).Warning(4).Build()
+			},
+			field: "duration",
+		},
+		{
+			name: "service metric",
+			build: func() (Monitor, error) {
+				return NewServiceMetricMonitorBuilder("cpu", "My-Service", "cpu").
+					Operator(MonitorOperatorGreater).Warning(80).Duration(1).MissingDuration(15, 0).
+					Build()
+			},
+			field: "missingDurationWarning",
+		},
+		{
+			name: "external HTTP",
+			build: func() (Monitor, error) {
+				return NewExternalHTTPMonitorBuilder("example.com", "https://example.com/").Header("", "value").Build()
+			},
+			field: "headers[0].name",
+		},
+		{
+			name: "expression",
+			build: func() (Monitor, error) {
+				return NewExpressionMonitorBuilder("expression", "max(role(My-Service:db, loadavg5))").Operator("=").Warning(4).Build()
+			},
+			field: "operator",
+		},
+		{
+			name: "anomaly detection",
+			build: func() (Monitor, error) {
+				return NewAnomalyDetectionMonitorBuilder("anomaly").WarningSensitivity(SensitivityNormal).Build()
+			},
+			field: "scopes",
+		},
+		{
+			name: "query",
+			build: func() (Monitor, error) {
+				return NewQueryMonitorBuilder("query", "").Operator(MonitorOperatorGreater).Warning(4).Build()
+			},
+			field: "query",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.build()
+			var verr *ValidationError
+			if !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Field != tt.field {
+				t.Errorf("Build should fail with the error of %s but: %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestMonitorBuilder_Reuse(t *testing.T) {
+	b := NewHostMetricMonitorBuilder("loadavg5", "loadavg5").
+		Operator(MonitorOperatorGreater).Warning(4).Duration(3).
+		Scopes("My-Service")
+	m1, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2, err := b.Scopes("Other-Service").ExcludeScopes("My-Service: db").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2.Scopes[0] = "Changed-Service"
+	if !slices.Equal(m1.Scopes, []string{"My-Service"}) || m1.ExcludeScopes != nil {
+		t.Errorf("the monitor built first should not be changed: %+v", m1)
+	}
+	if m3, _ := b.Build(); !slices.Equal(m3.Scopes, []string{"My-Service", "Other-Service"}) {
+		t.Errorf("the builder should not be changed by the monitors built: %+v", m3)
+	}
+
+	*m2.Warning = 10
+	if *m1.Warning != 4 {
+		t.Errorf("the thresholds should not be shared: %v", *m1.Warning)
+	}
+	if m3, _ := b.Build(); *m3.Warning != 4 {
+		t.Errorf("the builder should not be changed by the thresholds of the monitors built: %v", *m3.Warning)
+	}
+
+	h := NewExternalHTTPMonitorBuilder("example.com", "https://example.com/").Header("Accept", "text/html")
+	h1, _ := h.Build()
+	h2, _ := h.Header("Cache-Control", "no-cache").Build()
+	if len(h1.Headers) != 1 || len(h2.Headers) != 2 {
+		t.Errorf("headers should not be shared: %+v, %+v", h1.Headers, h2.Headers)
+	}
+	h3, _ := h.ExpectedStatusCode(200).Build()
+	h4, _ := h.Build()
+	if *h3.ExpectedStatusCode = 404; *h4.ExpectedStatusCode != 200 {
+		t.Errorf("the expected status codes should not be shared: %v", *h4.ExpectedStatusCode)
+	}
+
+	scopes := []string{"My-Service"}
+	a := NewAnomalyDetectionMonitorBuilder("anomaly", scopes...).WarningSensitivity(SensitivityNormal)
+	scopes[0] = "Changed-Service"
+	if a1, _ := a.Build(); !slices.Equal(a1.Scopes, []string{"My-Service"}) {
+		t.Errorf("the scopes passed should be copied: %+v", a1.Scopes)
+	}
+}
@@ -0,0 +1,154 @@
+package mackerel
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MonitorFieldDiff is a difference of a field between two monitors.
+type MonitorFieldDiff struct {
+	// Path is the path of the field in JSON, such as "warning" or "headers[0].value".
+	Path string
+	// Old and New are the values of the field. Pointers are dereferenced, and nil pointers are nil.
+	Old, New any
+}
+
+// DiffMonitor returns the differences from current, typically returned by the API, to desired
+// in the order of the fields. The following are not regarded as differences:
+//
+//   - ID, and Type left empty in either monitor.
+//   - Defaults filled by the API, such as MaxCheckAttempts 1 for 0 and AlertStatusOnGone "CRITICAL" for "".
+//   - Nil and empty slices, such as Scopes.
+//   - Nil Headers of desired, since the API keeps the headers for them.
+//
+// If the types of the monitors differ, the difference of "type" is returned alone.
+// Monitors of unknown types are compared by the top-level fields of their JSON.
+func DiffMonitor(current, desired Monitor) []MonitorFieldDiff {
+	if current.MonitorType() != desired.MonitorType() {
+		return []MonitorFieldDiff{{Path: "type", Old: current.MonitorType(), New: desired.MonitorType()}}
+	}
+	if !isKnownMonitor(current) || reflect.TypeOf(current) != reflect.TypeOf(desired) {
+		return diffMonitorJSON(current, desired)
+	}
+	c, d := normalizeMonitor(current), normalizeMonitor(desired)
+	if ext, ok := desired.(*MonitorExternalHTTP); ok && ext.Headers == nil {
+		c.FieldByName("Headers").SetZero()
+	}
+	var diffs []MonitorFieldDiff
+	diffValue("", c, d, &diffs)
+	return diffs
+}
+
+// EqualMonitor reports whether current and desired have no differences by [DiffMonitor].
+func EqualMonitor(current, desired Monitor) bool {
+	return len(DiffMonitor(current, desired)) == 0
+}
+
+func isKnownMonitor(m Monitor) bool {
+	switch m.(type) {
+	case *MonitorConnectivity, *MonitorHostMetric, *MonitorServiceMetric, *MonitorExternalHTTP,
+		*MonitorExpression, *MonitorAnomalyDetection, *MonitorQuery:
+		return true
+	}
+	return false
+}
+
+// normalizeMonitor returns a copy of the struct of m with the defaults of the API filled.
+func normalizeMonitor(m Monitor) reflect.Value {
+	v := reflect.New(reflect.TypeOf(m).Elem()).Elem()
+	v.Set(reflect.ValueOf(m).Elem())
+	v.FieldByName("ID").SetZero()
+	v.FieldByName("Type").SetString(m.MonitorType())
+	if f := v.FieldByName("MaxCheckAttempts"); f.IsValid() && f.Uint() == 0 {
+		f.SetUint(1)
+	}
+	switch m.(type) {
+	case *MonitorConnectivity:
+		if f := v.FieldByName("AlertStatusOnGone"); f.String() == "" {
+			f.SetString("CRITICAL")
+		}
+	case *MonitorExternalHTTP:
+		if f := v.FieldByName("Method"); f.String() == "" {
+			f.SetString("GET")
+		}
+	}
+	return v
+}
+
+func diffValue(path string, a, b reflect.Value, diffs *[]MonitorFieldDiff) {
+	switch a.Kind() {
+	case reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				*diffs = append(*diffs, MonitorFieldDiff{Path: path, Old: elemInterface(a), New: elemInterface(b)})
+			}
+			return
+		}
+		diffValue(path, a.Elem(), b.Elem(), diffs)
+	case reflect.Slice:
+		if a.Len() == 0 && b.Len() == 0 {
+			return
+		}
+		if a.Len() == b.Len() && a.Type().Elem().Kind() == reflect.Struct {
+			for i := range a.Len() {
+				diffValue(path+"["+strconv.Itoa(i)+"]", a.Index(i), b.Index(i), diffs)
+			}
+			return
+		}
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*diffs = append(*diffs, MonitorFieldDiff{Path: path, Old: a.Interface(), New: b.Interface()})
+		}
+	case reflect.Struct:
+		for i := range a.NumField() {
+			name, _, _ := strings.Cut(a.Type().Field(i).Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			diffValue(name, a.Field(i), b.Field(i), diffs)
+		}
+	default:
+		if !a.Equal(b) {
+			*diffs = append(*diffs, MonitorFieldDiff{Path: path, Old: a.Interface(), New: b.Interface()})
+		}
+	}
+}
+
+func elemInterface(v reflect.Value) any {
+	if v.IsNil() {
+		return nil
+	}
+	return v.Elem().Interface()
+}
+
+// diffMonitorJSON compares the top-level fields of the JSON objects of the monitors.
+// It is used for the monitors of unknown types.
+func diffMonitorJSON(current, desired Monitor) []MonitorFieldDiff {
+	var c, d map[string]any
+	if b, err := json.Marshal(current); err == nil {
+		json.Unmarshal(b, &c) // nolint
+	}
+	if b, err := json.Marshal(desired); err == nil {
+		json.Unmarshal(b, &d) // nolint
+	}
+	keys := make([]string, 0, len(c)+len(d))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	for k := range d {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	var diffs []MonitorFieldDiff
+	for _, k := range slices.Compact(keys) {
+		if k != "id" && !reflect.DeepEqual(c[k], d[k]) {
+			diffs = append(diffs, MonitorFieldDiff{Path: k, Old: c[k], New: d[k]})
+		}
+	}
+	return diffs
+}
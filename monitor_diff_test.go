@@ -0,0 +1,87 @@
+package mackerel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffMonitor(t *testing.T) {
+	tests := []struct {
+		name             string
+		current, desired Monitor
+		want             []MonitorFieldDiff
+	}{
+		{
+			name: "server defaults",
+			current: &MonitorHostMetric{
+				ID: "2cSZzK3XfmG", Type: "host", Name: "loadavg5", Metric: "loadavg5", Operator: ">",
+				Warning: pfloat64(4), Critical: pfloat64(8), Duration: 3, MaxCheckAttempts: 1, Scopes: []string{},
+			},
+			desired: &MonitorHostMetric{
+				Name: "loadavg5", Metric: "loadavg5", Operator: ">",
+				Warning: pfloat64(4), Critical: pfloat64(8), Duration: 3,
+			},
+		},
+		{
+			name: "thresholds",
+			current: &MonitorHostMetric{
+				ID: "2cSZzK3XfmG", Type: "host", Name: "loadavg5", Metric: "loadavg5", Operator: ">",
+				Warning: pfloat64(4), Critical: pfloat64(8), Duration: 3,
+			},
+			desired: &MonitorHostMetric{
+				Name: "loadavg5", Metric: "loadavg5", Operator: ">",
+				Critical: pfloat64(10), Duration: 3, Scopes: []string{"My-Service"},
+			},
+			want: []MonitorFieldDiff{
+				{Path: "warning", Old: 4.0, New: nil},
+				{Path: "critical", Old: 8.0, New: 10.0},
+				{Path: "scopes", Old: []string(nil), New: []string{"My-Service"}},
+			},
+		},
+		{
+			name: "headers",
+			current: &MonitorExternalHTTP{
+				Type: "external", Name: "example.com", Method: "GET", URL: "https://example.com/",
+				Headers: []HeaderField{{Name: "Cache-Control", Value: "no-cache"}},
+			},
+			desired: &MonitorExternalHTTP{
+				Name: "example.com", URL: "https://example.com/",
+				Headers: []HeaderField{{Name: "Cache-Control", Value: "max-age=0"}},
+			},
+			want: []MonitorFieldDiff{
+				{Path: "headers[0].value", Old: "no-cache", New: "max-age=0"},
+			},
+		},
+		{
+			name: "nil headers",
+			current: &MonitorExternalHTTP{
+				Type: "external", Name: "example.com", Method: "GET", URL: "https://example.com/",
+				Headers: []HeaderField{{Name: "Cache-Control", Value: "no-cache"}},
+			},
+			desired: &MonitorExternalHTTP{Name: "example.com", URL: "https://example.com/"},
+		},
+		{
+			name:    "type",
+			current: &MonitorConnectivity{Type: "connectivity"},
+			desired: &MonitorHostMetric{},
+			want:    []MonitorFieldDiff{{Path: "type", Old: "connectivity", New: "host"}},
+		},
+		{
+			name:    "unknown",
+			current: &MonitorUnknown{ID: "2cSZzK3XfmG", Type: "new", Raw: []byte(`{"id":"2cSZzK3XfmG","type":"new","threshold":1}`)},
+			desired: &MonitorUnknown{Type: "new", Raw: []byte(`{"type":"new","threshold":2}`)},
+			want:    []MonitorFieldDiff{{Path: "threshold", Old: 1.0, New: 2.0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffMonitor(tt.current, tt.desired)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffMonitor should return %#v but: %#v", tt.want, got)
+			}
+			if EqualMonitor(tt.current, tt.desired) != (len(tt.want) == 0) {
+				t.Errorf("EqualMonitor should be consistent with DiffMonitor")
+			}
+		})
+	}
+}
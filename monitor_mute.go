@@ -0,0 +1,287 @@
+package mackerel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MonitorSelector selects monitors for bulk operations such as [Client.MuteMonitorsContext].
+// Monitors matching all the set conditions are selected.
+type MonitorSelector struct {
+	// IDs selects the monitors of the IDs.
+	IDs []string
+	// Name is a glob pattern of the names in the syntax of path.Match, such as "web-*".
+	Name string
+	// Scope selects the monitors whose scopes include the service or the role, such as "My-Service" or "My-Service: db".
+	// A service also matches the roles of it. The service of service metric and external HTTP monitors is their scope.
+	Scope string
+	// Type selects the monitors of the type, such as "host".
+	Type string
+}
+
+// Match reports whether m is selected by s.
+func (s *MonitorSelector) Match(m Monitor) bool {
+	if len(s.IDs) > 0 && !slices.Contains(s.IDs, m.MonitorID()) {
+		return false
+	}
+	if s.Name != "" {
+		if ok, _ := path.Match(s.Name, m.MonitorName()); !ok {
+			return false
+		}
+	}
+	if s.Type != "" && s.Type != m.MonitorType() {
+		return false
+	}
+	if s.Scope != "" && !slices.ContainsFunc(monitorScopes(m), func(scope string) bool {
+		return matchScope(s.Scope, scope)
+	}) {
+		return false
+	}
+	return true
+}
+
+// matchScope reports whether scope is contained in pattern. Invalid scopes match nothing.
+func matchScope(pattern, scope string) bool {
+	p, err := ParseMonitorScope(pattern)
+	if err != nil {
+		return false
+	}
+	s, err := ParseMonitorScope(scope)
+	return err == nil && p.Contains(s)
+}
+
+func monitorScopes(m Monitor) []string {
+	switch m := m.(type) {
+	case *MonitorConnectivity:
+		return m.Scopes
+	case *MonitorHostMetric:
+		return m.Scopes
+	case *MonitorAnomalyDetection:
+		return m.Scopes
+	case *MonitorServiceMetric:
+		return []string{m.Service}
+	case *MonitorExternalHTTP:
+		if m.Service != "" {
+			return []string{m.Service}
+		}
+	}
+	return nil
+}
+
+// isMonitorMuted reports whether m is muted.
+func isMonitorMuted(m Monitor) bool {
+	switch m := m.(type) {
+	case *MonitorConnectivity:
+		return m.IsMute
+	case *MonitorHostMetric:
+		return m.IsMute
+	case *MonitorServiceMetric:
+		return m.IsMute
+	case *MonitorExternalHTTP:
+		return m.IsMute
+	case *MonitorExpression:
+		return m.IsMute
+	case *MonitorAnomalyDetection:
+		return m.IsMute
+	case *MonitorQuery:
+		return m.IsMute
+	case *MonitorUnknown:
+		var v struct {
+			IsMute bool `json:"isMute"`
+		}
+		json.Unmarshal(m.Raw, &v) // nolint
+		return v.IsMute
+	}
+	return false
+}
+
+// setMonitorMuted sets whether m is muted.
+func setMonitorMuted(m Monitor, mute bool) error {
+	switch m := m.(type) {
+	case *MonitorConnectivity:
+		m.IsMute = mute
+	case *MonitorHostMetric:
+		m.IsMute = mute
+	case *MonitorServiceMetric:
+		m.IsMute = mute
+	case *MonitorExternalHTTP:
+		m.IsMute = mute
+	case *MonitorExpression:
+		m.IsMute = mute
+	case *MonitorAnomalyDetection:
+		m.IsMute = mute
+	case *MonitorQuery:
+		m.IsMute = mute
+	case *MonitorUnknown:
+		var obj map[string]any
+		if err := json.Unmarshal(m.Raw, &obj); err != nil {
+			return err
+		}
+		obj["isMute"] = mute
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		m.Raw = raw
+	default:
+		return fmt.Errorf("cannot mute monitor of %T", m)
+	}
+	return nil
+}
+
+// MuteMonitor mutes a monitor.
+func (c *Client) MuteMonitor(monitorID string) (Monitor, error) {
+	return c.MuteMonitorContext(context.Background(), monitorID)
+}
+
+// MuteMonitorContext mutes a monitor.
+// It gets the latest monitor and updates it only if it is not muted yet,
+// which keeps the window of races with other editors small.
+func (c *Client) MuteMonitorContext(ctx context.Context, monitorID string) (Monitor, error) {
+	return c.setMonitorMute(ctx, monitorID, true)
+}
+
+// UnmuteMonitor unmutes a monitor.
+func (c *Client) UnmuteMonitor(monitorID string) (Monitor, error) {
+	return c.UnmuteMonitorContext(context.Background(), monitorID)
+}
+
+// UnmuteMonitorContext unmutes a monitor in the same way as MuteMonitorContext.
+func (c *Client) UnmuteMonitorContext(ctx context.Context, monitorID string) (Monitor, error) {
+	return c.setMonitorMute(ctx, monitorID, false)
+}
+
+func (c *Client) setMonitorMute(ctx context.Context, monitorID string, mute bool) (Monitor, error) {
+	m, err := c.GetMonitorContext(ctx, monitorID)
+	if err != nil {
+		return nil, err
+	}
+	if isMonitorMuted(m) == mute {
+		return m, nil
+	}
+	if err := setMonitorMuted(m, mute); err != nil {
+		return nil, err
+	}
+	return c.UpdateMonitorContext(ctx, monitorID, m)
+}
+
+// BulkMonitorOptions configures bulk operations on monitors.
+type BulkMonitorOptions struct {
+	// Concurrency is the maximum number of monitors processed at once. The default is 4.
+	Concurrency int
+}
+
+const defaultBulkMonitorConcurrency = 4
+
+// MonitorResult is the result of a bulk operation on a monitor.
+type MonitorResult struct {
+	MonitorID string
+	// Monitor is the monitor after the operation, nil on failure.
+	Monitor Monitor
+	Err     error
+}
+
+// MuteMonitors mutes the monitors selected by sel.
+func (c *Client) MuteMonitors(sel *MonitorSelector, opts *BulkMonitorOptions) ([]*MonitorResult, error) {
+	return c.MuteMonitorsContext(context.Background(), sel, opts)
+}
+
+// MuteMonitorsContext mutes the monitors selected by sel concurrently by MuteMonitorContext.
+// It returns the results in the order of FindMonitors, and the errors of the failed monitors joined.
+// opts may be nil.
+func (c *Client) MuteMonitorsContext(ctx context.Context, sel *MonitorSelector, opts *BulkMonitorOptions) ([]*MonitorResult, error) {
+	return c.bulkMonitors(ctx, sel, opts, c.MuteMonitorContext)
+}
+
+// UnmuteMonitors unmutes the monitors selected by sel.
+func (c *Client) UnmuteMonitors(sel *MonitorSelector, opts *BulkMonitorOptions) ([]*MonitorResult, error) {
+	return c.UnmuteMonitorsContext(context.Background(), sel, opts)
+}
+
+// UnmuteMonitorsContext unmutes the monitors selected by sel in the same way as MuteMonitorsContext.
+func (c *Client) UnmuteMonitorsContext(ctx context.Context, sel *MonitorSelector, opts *BulkMonitorOptions) ([]*MonitorResult, error) {
+	return c.bulkMonitors(ctx, sel, opts, c.UnmuteMonitorContext)
+}
+
+// SelectMonitors returns the monitors selected by sel.
+func (c *Client) SelectMonitors(sel *MonitorSelector) ([]Monitor, error) {
+	return c.SelectMonitorsContext(context.Background(), sel)
+}
+
+// SelectMonitorsContext returns the monitors selected by sel.
+func (c *Client) SelectMonitorsContext(ctx context.Context, sel *MonitorSelector) ([]Monitor, error) {
+	monitors, err := c.FindMonitorsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(monitors, func(m Monitor) bool { return !sel.Match(m) }), nil
+}
+
+func (c *Client) bulkMonitors(ctx context.Context, sel *MonitorSelector, opts *BulkMonitorOptions, op func(context.Context, string) (Monitor, error)) ([]*MonitorResult, error) {
+	monitors, err := c.SelectMonitorsContext(ctx, sel)
+	if err != nil {
+		return nil, err
+	}
+	concurrency := defaultBulkMonitorConcurrency
+	if opts != nil && opts.Concurrency > 0 {
+		concurrency = opts.Concurrency
+	}
+	results := make([]*MonitorResult, len(monitors))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, m := range monitors {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			r := &MonitorResult{MonitorID: m.MonitorID()}
+			r.Monitor, r.Err = op(ctx, r.MonitorID)
+			results[i] = r
+		})
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("monitor %s: %w", r.MonitorID, r.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// MuteMonitorsFor mutes the monitors selected by sel for d.
+func (c *Client) MuteMonitorsFor(sel *MonitorSelector, d time.Duration) (*Downtime, error) {
+	return c.MuteMonitorsForContext(context.Background(), sel, d)
+}
+
+// MuteMonitorsForContext mutes the monitors selected by sel for d, rounded up to minutes,
+// by creating a downtime of them starting now. The monitors are unmuted when the downtime ends
+// without any clients running, and can be unmuted earlier by deleting the downtime.
+func (c *Client) MuteMonitorsForContext(ctx context.Context, sel *MonitorSelector, d time.Duration) (*Downtime, error) {
+	if d <= 0 {
+		return nil, fmt.Errorf("duration must be positive: %s", d)
+	}
+	monitors, err := c.SelectMonitorsContext(ctx, sel)
+	if err != nil {
+		return nil, err
+	}
+	if len(monitors) == 0 {
+		return nil, errors.New("no monitors are selected")
+	}
+	ids := make([]string, len(monitors))
+	for i, m := range monitors {
+		ids[i] = m.MonitorID()
+	}
+	return c.CreateDowntimeContext(ctx, &Downtime{
+		Name:          fmt.Sprintf("Mute %d monitors", len(ids)),
+		Start:         time.Now().Unix(),
+		Duration:      int64((d + time.Minute - 1) / time.Minute),
+		MonitorScopes: ids,
+	})
+}
@@ -0,0 +1,135 @@
+package mackerel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newMonitorMuteTestServer(t *testing.T) (*httptest.Server, map[string]map[string]any, *[]map[string]any) {
+	var mu sync.Mutex
+	monitors := map[string]map[string]any{
+		"2cSZzK3XfmA": {"id": "2cSZzK3XfmA", "type": "connectivity", "name": "connectivity", "scopes": []any{"My-Service: db"}},
+		"2cSZzK3XfmB": {"id": "2cSZzK3XfmB", "type": "service", "name": "web-4xx", "service": "My-Service", "isMute": true},
+		"2cSZzK3XfmC": {"id": "2cSZzK3XfmC", "type": "new", "name": "web-new", "isMute": false},
+		"2cSZzK3XfmD": {"id": "2cSZzK3XfmD", "type": "host", "name": "web-cpu", "scopes": []any{"Other-Service"}},
+	}
+	var downtimes []map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		id := strings.TrimPrefix(req.URL.Path, "/api/v0/monitors/")
+		var v any
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/v0/monitors":
+			list := []map[string]any{}
+			for _, id := range []string{"2cSZzK3XfmA", "2cSZzK3XfmB", "2cSZzK3XfmC", "2cSZzK3XfmD"} {
+				list = append(list, monitors[id])
+			}
+			v = map[string]any{"monitors": list}
+		case req.Method == http.MethodGet:
+			v = map[string]any{"monitor": monitors[id]}
+		case req.Method == http.MethodPut:
+			var m map[string]any
+			json.NewDecoder(req.Body).Decode(&m) // nolint
+			monitors[id] = m
+			v = m
+		case req.Method == http.MethodPost && req.URL.Path == "/api/v0/downtimes":
+			var d map[string]any
+			json.NewDecoder(req.Body).Decode(&d) // nolint
+			d["id"] = "abcde"
+			downtimes = append(downtimes, d)
+			v = d
+		default:
+			t.Errorf("unexpected request: %s %s", req.Method, req.URL.Path)
+		}
+		json.NewEncoder(res).Encode(v) // nolint
+	}))
+	return ts, monitors, &downtimes
+}
+
+func TestMonitorSelector_Match(t *testing.T) {
+	m := &MonitorHostMetric{ID: "2cSZzK3XfmG", Name: "web-cpu", Scopes: []string{"My-Service: web"}}
+	tests := []struct {
+		sel  MonitorSelector
+		want bool
+	}{
+		{MonitorSelector{}, true},
+		{MonitorSelector{IDs: []string{"2cSZzK3XfmG"}}, true},
+		{MonitorSelector{IDs: []string{"2cSZzK3XfmA"}}, false},
+		{MonitorSelector{Name: "web-*", Type: "host"}, true},
+		{MonitorSelector{Name: "db-*"}, false},
+		{MonitorSelector{Type: "service"}, false},
+		{MonitorSelector{Scope: "My-Service"}, true},
+		{MonitorSelector{Scope: "My-Service: web"}, true},
+		{MonitorSelector{Scope: "My-Service: db"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.sel.Match(m); got != tt.want {
+			t.Errorf("%+v: Match should be %v but: %v", tt.sel, tt.want, got)
+		}
+	}
+}
+
+func TestMuteMonitors(t *testing.T) {
+	ts, monitors, _ := newMonitorMuteTestServer(t)
+	defer ts.Close()
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+
+	results, err := client.MuteMonitorsContext(t.Context(), &MonitorSelector{Scope: "My-Service"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].MonitorID != "2cSZzK3XfmA" || results[1].MonitorID != "2cSZzK3XfmB" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if monitors["2cSZzK3XfmA"]["isMute"] != true || monitors["2cSZzK3XfmD"]["isMute"] != nil {
+		t.Errorf("only selected monitors should be muted: %+v", monitors)
+	}
+
+	results, err = client.UnmuteMonitorsContext(t.Context(), &MonitorSelector{Name: "web-*"}, &BulkMonitorOptions{Concurrency: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if monitors["2cSZzK3XfmB"]["isMute"] != nil || monitors["2cSZzK3XfmA"]["isMute"] != true {
+		t.Errorf("only selected monitors should be unmuted: %+v", monitors)
+	}
+	if m, ok := results[1].Monitor.(*MonitorUnknown); !ok || isMonitorMuted(m) {
+		t.Errorf("unknown monitor should be returned as is: %+v", results[1].Monitor)
+	}
+
+	m, err := client.MuteMonitorContext(t.Context(), "2cSZzK3XfmC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isMonitorMuted(m) || monitors["2cSZzK3XfmC"]["isMute"] != true || monitors["2cSZzK3XfmC"]["name"] != "web-new" {
+		t.Errorf("unknown monitor should be muted keeping fields: %+v", monitors["2cSZzK3XfmC"])
+	}
+}
+
+func TestMuteMonitorsFor(t *testing.T) {
+	ts, _, downtimes := newMonitorMuteTestServer(t)
+	defer ts.Close()
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+
+	downtime, err := client.MuteMonitorsForContext(t.Context(), &MonitorSelector{Type: "host"}, 90*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if downtime.ID != "abcde" || downtime.Duration != 2 || len(downtime.MonitorScopes) != 1 || downtime.MonitorScopes[0] != "2cSZzK3XfmD" {
+		t.Errorf("unexpected downtime: %+v", downtime)
+	}
+	if len(*downtimes) != 1 {
+		t.Errorf("a downtime should be created but: %+v", *downtimes)
+	}
+	if _, err := client.MuteMonitorsForContext(t.Context(), &MonitorSelector{Type: "expression"}, time.Hour); err == nil {
+		t.Error("MuteMonitorsFor should fail without selected monitors")
+	}
+}
@@ -0,0 +1,187 @@
+package mackerel
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MonitorScope is a service, or a role of a service, that monitors, downtimes and alert group settings target.
+// It is formatted as "Service" or "Service: Role" in fields such as MonitorHostMetric.Scopes and Downtime.RoleScopes.
+type MonitorScope struct {
+	Service string
+	// Role is empty for a scope of the whole service.
+	Role string
+}
+
+// ServiceScope returns the scope of the service.
+func ServiceScope(service string) MonitorScope {
+	return MonitorScope{Service: service}
+}
+
+// RoleScope returns the scope of the role of the service.
+func RoleScope(service, role string) MonitorScope {
+	return MonitorScope{Service: service, Role: role}
+}
+
+// ParseMonitorScope parses s in the format of "Service" or "Service: Role". Spaces around the names are ignored.
+func ParseMonitorScope(s string) (MonitorScope, error) {
+	service, role, hasRole := strings.Cut(s, ":")
+	scope := MonitorScope{Service: strings.TrimSpace(service), Role: strings.TrimSpace(role)}
+	if scope.Service == "" || hasRole && scope.Role == "" || strings.Contains(role, ":") {
+		return MonitorScope{}, fmt.Errorf("invalid scope: %q", s)
+	}
+	return scope, nil
+}
+
+// ParseMonitorScopes parses each of ss by ParseMonitorScope.
+func ParseMonitorScopes(ss []string) ([]MonitorScope, error) {
+	scopes := make([]MonitorScope, 0, len(ss))
+	for _, s := range ss {
+		scope, err := ParseMonitorScope(s)
+		if err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
+// FormatMonitorScopes formats each of scopes by its String method.
+func FormatMonitorScopes(scopes []MonitorScope) []string {
+	ss := make([]string, len(scopes))
+	for i, scope := range scopes {
+		ss[i] = scope.String()
+	}
+	return ss
+}
+
+// String returns s in the format of "Service" or "Service: Role".
+func (s MonitorScope) String() string {
+	if s.Role == "" {
+		return s.Service
+	}
+	return s.Service + ": " + s.Role
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s MonitorScope) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *MonitorScope) UnmarshalText(text []byte) error {
+	scope, err := ParseMonitorScope(string(text))
+	if err != nil {
+		return err
+	}
+	*s = scope
+	return nil
+}
+
+// IsRole reports whether s is a scope of a role.
+func (s MonitorScope) IsRole() bool {
+	return s.Role != ""
+}
+
+// Contains reports whether t is s or a role of the service s.
+func (s MonitorScope) Contains(t MonitorScope) bool {
+	return s.Service == t.Service && (s.Role == "" || s.Role == t.Role)
+}
+
+// ContainsHost reports whether the host belongs to s.
+func (s MonitorScope) ContainsHost(host *Host) bool {
+	roles, ok := host.Roles[s.Service]
+	return ok && (s.Role == "" || slices.Contains(roles, s.Role))
+}
+
+// ValidateMonitorScopes checks that the services and the roles of scopes exist.
+func (c *Client) ValidateMonitorScopes(scopes []MonitorScope) error {
+	return c.ValidateMonitorScopesContext(context.Background(), scopes)
+}
+
+// ValidateMonitorScopesContext checks that the services and the roles of scopes exist.
+// It returns *ValidationError, which matches ErrValidation, reporting the scopes not found.
+func (c *Client) ValidateMonitorScopesContext(ctx context.Context, scopes []MonitorScope) error {
+	services, err := c.FindServicesContext(ctx)
+	if err != nil {
+		return err
+	}
+	roles := make(map[string][]string, len(services))
+	for _, s := range services {
+		roles[s.Name] = s.Roles
+	}
+	var fs fieldErrors
+	for _, scope := range scopes {
+		rs, ok := roles[scope.Service]
+		switch {
+		case !ok:
+			fs.add(scope.String(), "service is not found")
+		case scope.IsRole() && !slices.Contains(rs, scope.Role):
+			fs.add(scope.String(), "role is not found")
+		}
+	}
+	return fs.err()
+}
+
+// FindMonitorHosts finds the hosts that the monitor covers.
+func (c *Client) FindMonitorHosts(m Monitor) ([]*Host, error) {
+	return c.FindMonitorHostsContext(context.Background(), m)
+}
+
+// FindMonitorHostsContext finds the hosts that the monitor covers by its scopes and exclude scopes.
+// Connectivity and host metric monitors without scopes cover all the hosts.
+// Monitors of the other types, which do not monitor hosts, cover no hosts.
+// The hosts are found by FindHostsContext, so retired hosts are not included.
+func (c *Client) FindMonitorHostsContext(ctx context.Context, m Monitor) ([]*Host, error) {
+	var scopes, excludeScopes []string
+	switch m := m.(type) {
+	case *MonitorConnectivity:
+		scopes, excludeScopes = m.Scopes, m.ExcludeScopes
+	case *MonitorHostMetric:
+		scopes, excludeScopes = m.Scopes, m.ExcludeScopes
+	case *MonitorAnomalyDetection:
+		if len(m.Scopes) == 0 {
+			return nil, nil
+		}
+		scopes = m.Scopes
+	default:
+		return nil, nil
+	}
+	includes, err := ParseMonitorScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+	excludes, err := ParseMonitorScopes(excludeScopes)
+	if err != nil {
+		return nil, err
+	}
+
+	var hosts []*Host
+	if len(includes) == 0 {
+		if hosts, err = c.FindHostsContext(ctx, &FindHostsParam{}); err != nil {
+			return nil, err
+		}
+	}
+	seen := make(map[string]bool)
+	for _, scope := range includes {
+		param := &FindHostsParam{Service: scope.Service}
+		if scope.IsRole() {
+			param.Roles = []string{scope.Role}
+		}
+		hs, err := c.FindHostsContext(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range hs {
+			if !seen[h.ID] {
+				seen[h.ID] = true
+				hosts = append(hosts, h)
+			}
+		}
+	}
+	return slices.DeleteFunc(hosts, func(h *Host) bool {
+		return slices.ContainsFunc(excludes, func(s MonitorScope) bool { return s.ContainsHost(h) })
+	}), nil
+}
@@ -0,0 +1,128 @@
+package mackerel
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestParseMonitorScope(t *testing.T) {
+	tests := []struct {
+		in   string
+		want MonitorScope
+		ok   bool
+	}{
+		{"My-Service", ServiceScope("My-Service"), true},
+		{"My-Service: db", RoleScope("My-Service", "db"), true},
+		{" My-Service:db ", RoleScope("My-Service", "db"), true},
+		{"", MonitorScope{}, false},
+		{"My-Service:", MonitorScope{}, false},
+		{": db", MonitorScope{}, false},
+		{"My-Service: db: x", MonitorScope{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseMonitorScope(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseMonitorScope(%q) should be %+v (ok: %v) but: %+v, %v", tt.in, tt.want, tt.ok, got, err)
+		}
+	}
+	if s := RoleScope("My-Service", "db").String(); s != "My-Service: db" {
+		t.Errorf("String should be formatted but: %q", s)
+	}
+
+	var v struct {
+		Scopes []MonitorScope `json:"scopes"`
+	}
+	if err := json.Unmarshal([]byte(`{"scopes":["My-Service","My-Service: db"]}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(FormatMonitorScopes(v.Scopes), []string{"My-Service", "My-Service: db"}) {
+		t.Errorf("unexpected scopes: %+v", v.Scopes)
+	}
+	if !ServiceScope("My-Service").Contains(v.Scopes[1]) || v.Scopes[1].Contains(v.Scopes[0]) {
+		t.Error("service scopes should contain their role scopes only")
+	}
+}
+
+func TestValidateMonitorScopes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(`{"services":[{"name":"My-Service","memo":"","roles":["db","web"]}]}`)) // nolint
+	}))
+	defer ts.Close()
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+
+	if err := client.ValidateMonitorScopesContext(t.Context(), []MonitorScope{ServiceScope("My-Service"), RoleScope("My-Service", "web")}); err != nil {
+		t.Error("scopes should be valid but: ", err)
+	}
+	err := client.ValidateMonitorScopesContext(t.Context(), []MonitorScope{RoleScope("My-Service", "proxy"), ServiceScope("Other-Service")})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || !errors.Is(err, ErrValidation) || len(verr.Fields) != 2 || verr.Fields[0].Field != "My-Service: proxy" {
+		t.Error("scopes should be invalid but: ", err)
+	}
+}
+
+func TestFindMonitorHosts(t *testing.T) {
+	hosts := map[string]*Host{
+		"db1":    {ID: "db1", Roles: Roles{"My-Service": {"db"}}},
+		"web1":   {ID: "web1", Roles: Roles{"My-Service": {"web"}}},
+		"web2":   {ID: "web2", Roles: Roles{"My-Service": {"web", "batch"}}},
+		"other1": {ID: "other1", Roles: Roles{"Other-Service": {"web"}}},
+	}
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		queries = append(queries, req.URL.RawQuery)
+		service, roles := req.URL.Query().Get("service"), req.URL.Query()["role"]
+		var found []*Host
+		for _, id := range []string{"db1", "web1", "web2", "other1"} {
+			h := hosts[id]
+			if service != "" && !(MonitorScope{Service: service}).ContainsHost(h) {
+				continue
+			}
+			if len(roles) > 0 && !(MonitorScope{Service: service, Role: roles[0]}).ContainsHost(h) {
+				continue
+			}
+			found = append(found, h)
+		}
+		json.NewEncoder(res).Encode(map[string]any{"hosts": found}) // nolint
+	}))
+	defer ts.Close()
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+
+	ids := func(hs []*Host) []string {
+		var ids []string
+		for _, h := range hs {
+			ids = append(ids, h.ID)
+		}
+		return ids
+	}
+	got, err := client.FindMonitorHostsContext(t.Context(), &MonitorHostMetric{
+		Scopes:        []string{"My-Service: web", "Other-Service"},
+		ExcludeScopes: []string{"My-Service: batch"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids(got), []string{"web1", "other1"}) {
+		t.Errorf("unexpected hosts: %v", ids(got))
+	}
+	if !reflect.DeepEqual(queries, []string{"role=web&service=My-Service", "service=Other-Service"}) {
+		t.Errorf("unexpected queries: %v", queries)
+	}
+
+	got, err = client.FindMonitorHostsContext(t.Context(), &MonitorConnectivity{ExcludeScopes: []string{"My-Service"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids(got), []string{"other1"}) {
+		t.Errorf("unexpected hosts: %v", ids(got))
+	}
+
+	got, err = client.FindMonitorHostsContext(t.Context(), &MonitorServiceMetric{Service: "My-Service"})
+	if err != nil || got != nil {
+		t.Errorf("service metric monitors should cover no hosts but: %v, %v", got, err)
+	}
+}
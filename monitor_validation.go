@@ -0,0 +1,247 @@
+package mackerel
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Monitor operators
+const (
+	MonitorOperatorGreater = ">"
+	MonitorOperatorLess    = "<"
+)
+
+// Sensitivities of anomaly detection monitors
+const (
+	SensitivityInsensitive = "insensitive"
+	SensitivityNormal      = "normal"
+	SensitivitySensitive   = "sensitive"
+)
+
+const (
+	monitorMaxDuration         = 10
+	monitorMaxCheckAttempts    = 10
+	monitorMinNotification     = 10
+	monitorMaxMissingDuration  = 7 * 24 * 60
+	monitorMissingDurationUnit = 10
+)
+
+var (
+	monitorOperators          = []string{MonitorOperatorGreater, MonitorOperatorLess}
+	monitorSensitivities      = []string{SensitivityInsensitive, SensitivityNormal, SensitivitySensitive}
+	monitorAlertStatusesGone  = []string{"CRITICAL", "WARNING"}
+	monitorExternalHTTPMethod = []string{"GET", "POST", "PUT", "DELETE"}
+)
+
+// ValidationError reports the fields rejected by client-side validation, such as [Monitor.Validate].
+// It matches [ErrValidation] with errors.Is like validation errors reported by the API.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (err *ValidationError) Error() string {
+	msgs := make([]string, len(err.Fields))
+	for i, f := range err.Fields {
+		msgs[i] = f.Field + ": " + f.Message
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// Is reports whether target is ErrValidation.
+func (err *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// fieldErrors collects the errors of the fields in the order they are checked.
+type fieldErrors []FieldError
+
+func (fs *fieldErrors) add(field, format string, v ...any) {
+	*fs = append(*fs, FieldError{Field: field, Message: fmt.Sprintf(format, v...)})
+}
+
+func (fs fieldErrors) err() error {
+	if len(fs) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: fs}
+}
+
+func (fs *fieldErrors) checkRequired(field, v string) {
+	if v == "" {
+		fs.add(field, "must not be empty")
+	}
+}
+
+func (fs *fieldErrors) checkOneOf(field, v string, values []string) {
+	if v != "" && !slices.Contains(values, v) {
+		fs.add(field, "must be one of %s but %q", strings.Join(values, ", "), v)
+	}
+}
+
+func (fs *fieldErrors) checkRange(field string, v, lower, upper uint64) {
+	if v < lower || v > upper {
+		fs.add(field, "must be between %d and %d but %d", lower, upper, v)
+	}
+}
+
+// checkCommon checks the fields that all the monitor types have. Empty typ is allowed.
+func (fs *fieldErrors) checkCommon(m Monitor, typ string, notificationInterval uint64) {
+	if typ != "" && typ != m.MonitorType() {
+		fs.add("type", "must be %q but %q", m.MonitorType(), typ)
+	}
+	if _, ok := m.(*MonitorConnectivity); !ok {
+		fs.checkRequired("name", m.MonitorName())
+	}
+	if notificationInterval != 0 && notificationInterval < monitorMinNotification {
+		fs.add("notificationInterval", "must be %d or more but %d", monitorMinNotification, notificationInterval)
+	}
+}
+
+func (fs *fieldErrors) checkMaxCheckAttempts(n uint64) {
+	if n != 0 {
+		fs.checkRange("maxCheckAttempts", n, 1, monitorMaxCheckAttempts)
+	}
+}
+
+// checkThresholds checks operator, warning and critical of the monitors comparing a value with thresholds.
+func (fs *fieldErrors) checkThresholds(operator string, warning, critical *float64) {
+	fs.checkRequired("operator", operator)
+	fs.checkOneOf("operator", operator, monitorOperators)
+	if warning == nil && critical == nil {
+		fs.add("warning", "either warning or critical must be set")
+		return
+	}
+	if warning == nil || critical == nil {
+		return
+	}
+	switch operator {
+	case MonitorOperatorGreater:
+		if *warning > *critical {
+			fs.add("warning", "must not be greater than critical with operator %q", operator)
+		}
+	case MonitorOperatorLess:
+		if *warning < *critical {
+			fs.add("warning", "must not be less than critical with operator %q", operator)
+		}
+	}
+}
+
+func (fs *fieldErrors) checkMissingDuration(field string, d uint64) {
+	if d == 0 {
+		return
+	}
+	if d%monitorMissingDurationUnit != 0 {
+		fs.add(field, "must be a multiple of %d but %d", monitorMissingDurationUnit, d)
+	}
+	if d > monitorMaxMissingDuration {
+		fs.add(field, "must be %d or less but %d", monitorMaxMissingDuration, d)
+	}
+}
+
+// Validate checks m against the constraints documented for connectivity monitors.
+func (m *MonitorConnectivity) Validate() error {
+	var fs fieldErrors
+	fs.checkCommon(m, m.Type, m.NotificationInterval)
+	fs.checkOneOf("alertStatusOnGone", m.AlertStatusOnGone, monitorAlertStatusesGone)
+	return fs.err()
+}
+
+// Validate checks m against the constraints documented for host metric monitors.
+func (m *MonitorHostMetric) Validate() error {
+	var fs fieldErrors
+	fs.checkCommon(m, m.Type, m.NotificationInterval)
+	fs.checkRequired("metric", m.Metric)
+	fs.checkThresholds(m.Operator, m.Warning, m.Critical)
+	fs.checkRange("duration", m.Duration, 1, monitorMaxDuration)
+	fs.checkMaxCheckAttempts(m.MaxCheckAttempts)
+	return fs.err()
+}
+
+// Validate checks m against the constraints documented for service metric monitors.
+func (m *MonitorServiceMetric) Validate() error {
+	var fs fieldErrors
+	fs.checkCommon(m, m.Type, m.NotificationInterval)
+	fs.checkRequired("service", m.Service)
+	fs.checkRequired("metric", m.Metric)
+	fs.checkThresholds(m.Operator, m.Warning, m.Critical)
+	fs.checkRange("duration", m.Duration, 1, monitorMaxDuration)
+	fs.checkMaxCheckAttempts(m.MaxCheckAttempts)
+	fs.checkMissingDuration("missingDurationWarning", m.MissingDurationWarning)
+	fs.checkMissingDuration("missingDurationCritical", m.MissingDurationCritical)
+	if w, c := m.MissingDurationWarning, m.MissingDurationCritical; w != 0 && c != 0 && w > c {
+		fs.add("missingDurationWarning", "must not be greater than missingDurationCritical")
+	}
+	return fs.err()
+}
+
+// Validate checks m against the constraints documented for external HTTP monitors.
+func (m *MonitorExternalHTTP) Validate() error {
+	var fs fieldErrors
+	fs.checkCommon(m, m.Type, m.NotificationInterval)
+	if m.URL == "" {
+		fs.add("url", "must not be empty")
+	} else if u, err := url.Parse(m.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fs.add("url", "must be an absolute http or https URL but %q", m.URL)
+	}
+	fs.checkOneOf("method", m.Method, monitorExternalHTTPMethod)
+	fs.checkMaxCheckAttempts(m.MaxCheckAttempts)
+	if m.ResponseTimeDuration != nil {
+		if m.ResponseTimeWarning == nil && m.ResponseTimeCritical == nil {
+			fs.add("responseTimeDuration", "requires responseTimeWarning or responseTimeCritical")
+		}
+		fs.checkRange("responseTimeDuration", *m.ResponseTimeDuration, 1, monitorMaxDuration)
+	} else if m.ResponseTimeWarning != nil || m.ResponseTimeCritical != nil {
+		fs.add("responseTimeDuration", "must be set with responseTimeWarning or responseTimeCritical")
+	}
+	if w, c := m.ResponseTimeWarning, m.ResponseTimeCritical; w != nil && c != nil && *w > *c {
+		fs.add("responseTimeWarning", "must not be greater than responseTimeCritical")
+	}
+	if w, c := m.CertificationExpirationWarning, m.CertificationExpirationCritical; w != nil && c != nil && *w < *c {
+		fs.add("certificationExpirationWarning", "must not be less than certificationExpirationCritical")
+	}
+	if code := m.ExpectedStatusCode; code != nil && (*code < 100 || *code > 599) {
+		fs.add("expectedStatusCode", "must be between 100 and 599 but %d", *code)
+	}
+	for i, h := range m.Headers {
+		if h.Name == "" {
+			fs.add(fmt.Sprintf("headers[%d].name", i), "must not be empty")
+		}
+	}
+	return fs.err()
+}
+
+// Validate checks m against the constraints documented for expression monitors.
+func (m *MonitorExpression) Validate() error {
+	var fs fieldErrors
+	fs.checkCommon(m, m.Type, m.NotificationInterval)
+	fs.checkRequired("expression", m.Expression)
+	fs.checkThresholds(m.Operator, m.Warning, m.Critical)
+	return fs.err()
+}
+
+// Validate checks m against the constraints documented for anomaly detection monitors.
+func (m *MonitorAnomalyDetection) Validate() error {
+	var fs fieldErrors
+	fs.checkCommon(m, m.Type, m.NotificationInterval)
+	if m.WarningSensitivity == "" && m.CriticalSensitivity == "" {
+		fs.add("warningSensitivity", "either warningSensitivity or criticalSensitivity must be set")
+	}
+	fs.checkOneOf("warningSensitivity", m.WarningSensitivity, monitorSensitivities)
+	fs.checkOneOf("criticalSensitivity", m.CriticalSensitivity, monitorSensitivities)
+	if len(m.Scopes) == 0 {
+		fs.add("scopes", "must not be empty")
+	}
+	fs.checkMaxCheckAttempts(m.MaxCheckAttempts)
+	return fs.err()
+}
+
+// Validate checks m against the constraints documented for query monitors.
+func (m *MonitorQuery) Validate() error {
+	var fs fieldErrors
+	fs.checkCommon(m, m.Type, m.NotificationInterval)
+	fs.checkRequired("query", m.Query)
+	fs.checkThresholds(m.Operator, m.Warning, m.Critical)
+	return fs.err()
+}
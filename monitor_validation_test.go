@@ -0,0 +1,105 @@
+package mackerel
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestMonitor_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		monitor Monitor
+		fields  []string
+	}{
+		{
+			name:    "connectivity",
+			monitor: &MonitorConnectivity{Type: "connectivity"},
+		},
+		{
+			name:    "connectivity with invalid alert status",
+			monitor: &MonitorConnectivity{AlertStatusOnGone: "OK", NotificationInterval: 5},
+			fields:  []string{"notificationInterval", "alertStatusOnGone"},
+		},
+		{
+			name: "host metric",
+			monitor: &MonitorHostMetric{
+				Name: "loadavg5", Metric: "loadavg5", Operator: ">",
+				Warning: pfloat64(4), Critical: pfloat64(8), Duration: 3,
+			},
+		},
+		{
+			name:    "host metric without operator and thresholds",
+			monitor: &MonitorHostMetric{Type: "service", Name: "loadavg5", Metric: "loadavg5", Duration: 11},
+			fields:  []string{"type", "operator", "warning", "duration"},
+		},
+		{
+			name: "host metric with warning greater than critical",
+			monitor: &MonitorHostMetric{
+				Name: "loadavg5", Metric: "loadavg5", Operator: ">",
+				Warning: pfloat64(8), Critical: pfloat64(4), Duration: 3,
+			},
+			fields: []string{"warning"},
+		},
+		{
+			name: "service metric with invalid missing durations",
+			monitor: &MonitorServiceMetric{
+				Name: "4xx", Service: "My-Service", Metric: "custom.4xx", Operator: "<",
+				Warning: pfloat64(10), Duration: 1, MissingDurationWarning: 15, MissingDurationCritical: 10,
+			},
+			fields: []string{"missingDurationWarning", "missingDurationWarning"},
+		},
+		{
+			name: "external",
+			monitor: &MonitorExternalHTTP{
+				Name: "example.com", Method: "GET", URL: "https://example.com/",
+				ResponseTimeWarning: pfloat64(5000), ResponseTimeCritical: pfloat64(10000), ResponseTimeDuration: puint64(5),
+			},
+		},
+		{
+			name: "external with invalid request",
+			monitor: &MonitorExternalHTTP{
+				Name: "example.com", Method: "PATCH", URL: "example.com", ResponseTimeDuration: puint64(5),
+			},
+			fields: []string{"url", "method", "responseTimeDuration"},
+		},
+		{
+			name: "anomaly detection",
+			monitor: &MonitorAnomalyDetection{
+				Name: "anomaly", WarningSensitivity: "sensitive", Scopes: []string{"My-Service"},
+			},
+		},
+		{
+			name:    "anomaly detection with invalid sensitivity",
+			monitor: &MonitorAnomalyDetection{Name: "anomaly", CriticalSensitivity: "high"},
+			fields:  []string{"criticalSensitivity", "scopes"},
+		},
+		{
+			name:    "query",
+			monitor: &MonitorQuery{Query: "container.cpu.utilization", Operator: "=", Critical: pfloat64(90)},
+			fields:  []string{"name", "operator"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.monitor.Validate()
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatal("Validate should succeed but: ", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) || !errors.Is(err, ErrValidation) {
+				t.Fatal("Validate should return *ValidationError but: ", err)
+			}
+			var fields []string
+			for _, f := range verr.Fields {
+				fields = append(fields, f.Field)
+			}
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("fields should be %v but: %v (%s)", tt.fields, fields, err)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sync"
 )
 
 /*
@@ -108,6 +109,10 @@ type Monitor interface {
 	MonitorID() string
 	MonitorName() string
 
+	// Validate checks the monitor offline against the constraints documented for its type.
+	// It returns *ValidationError, which matches ErrValidation.
+	Validate() error
+
 	isMonitor()
 }
 
@@ -130,6 +135,7 @@ var (
 	_ Monitor = (*MonitorExpression)(nil)
 	_ Monitor = (*MonitorAnomalyDetection)(nil)
 	_ Monitor = (*MonitorQuery)(nil)
+	_ Monitor = (*MonitorUnknown)(nil)
 )
 
 // Ensure only monitor types defined in this package can be assigned to the
@@ -141,6 +147,7 @@ func (m *MonitorExternalHTTP) isMonitor()     {}
 func (m *MonitorExpression) isMonitor()       {}
 func (m *MonitorAnomalyDetection) isMonitor() {}
 func (m *MonitorQuery) isMonitor()            {}
+func (m *MonitorUnknown) isMonitor()          {}
 
 // MonitorConnectivity represents connectivity monitor.
 type MonitorConnectivity struct {
@@ -345,6 +352,109 @@ func (m *MonitorQuery) MonitorName() string { return m.Name }
 // MonitorID returns monitor id.
 func (m *MonitorQuery) MonitorID() string { return m.ID }
 
+// MonitorUnknown represents a monitor of a type this package does not know.
+// It keeps the JSON from the API so that it can be sent back by UpdateMonitor
+// with the other fields as they are.
+type MonitorUnknown struct {
+	ID   string
+	Name string
+	Type string
+	// Raw is the JSON object of the monitor.
+	Raw json.RawMessage
+}
+
+// MonitorType returns monitor type.
+func (m *MonitorUnknown) MonitorType() string { return m.Type }
+
+// MonitorName returns monitor name.
+func (m *MonitorUnknown) MonitorName() string { return m.Name }
+
+// MonitorID returns monitor id.
+func (m *MonitorUnknown) MonitorID() string { return m.ID }
+
+// Validate does nothing since the constraints of the type are not known.
+func (m *MonitorUnknown) Validate() error { return nil }
+
+// MarshalJSON returns Raw with its id, name and type replaced by the fields of m.
+// Empty ID and Name remove them from the object.
+func (m *MonitorUnknown) MarshalJSON() ([]byte, error) {
+	if len(m.Raw) == 0 {
+		return json.Marshal(struct {
+			ID   string `json:"id,omitempty"`
+			Name string `json:"name,omitempty"`
+			Type string `json:"type"`
+		}{m.ID, m.Name, m.Type})
+	}
+	var v map[string]json.RawMessage
+	if err := json.Unmarshal(m.Raw, &v); err != nil {
+		return nil, err
+	}
+	if v == nil {
+		v = make(map[string]json.RawMessage)
+	}
+	for key, s := range map[string]string{"id": m.ID, "name": m.Name, "type": m.Type} {
+		if s == "" && key != "type" {
+			delete(v, key)
+			continue
+		}
+		b, err := json.Marshal(s)
+		if err != nil {
+			return nil, err
+		}
+		v[key] = b
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON keeps data as Raw and decodes its id, name and type.
+func (m *MonitorUnknown) UnmarshalJSON(data []byte) error {
+	var v struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	m.ID, m.Name, m.Type = v.ID, v.Name, v.Type
+	m.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// MonitorDecoder decodes the JSON of a monitor.
+type MonitorDecoder func(data json.RawMessage) (Monitor, error)
+
+var (
+	monitorDecodersMu sync.RWMutex
+	monitorDecoders   = map[string]MonitorDecoder{}
+)
+
+// RegisterMonitorDecoder registers decode for monitors of typ, which this package does not know yet.
+// Monitors of unregistered unknown types are decoded to *MonitorUnknown.
+// Since Monitor cannot be implemented outside this package,
+// decode usually returns a type that embeds MonitorUnknown and overrides its methods.
+// It panics if typ is a known monitor type or decode is nil.
+func RegisterMonitorDecoder(typ string, decode MonitorDecoder) {
+	switch typ {
+	case monitorTypeConnectivity, monitorTypeHostMetric, monitorTypeServiceMetric, monitorTypeExternalHTTP,
+		monitorTypeExpression, monitorTypeAnomalyDetection, monitorTypeQuery:
+		panic("mackerel: RegisterMonitorDecoder of known monitor type " + typ)
+	}
+	if decode == nil {
+		panic("mackerel: RegisterMonitorDecoder decoder is nil")
+	}
+	monitorDecodersMu.Lock()
+	defer monitorDecodersMu.Unlock()
+	monitorDecoders[typ] = decode
+}
+
+func lookupMonitorDecoder(typ string) (MonitorDecoder, bool) {
+	monitorDecodersMu.RLock()
+	defer monitorDecodersMu.RUnlock()
+	decode, ok := monitorDecoders[typ]
+	return decode, ok
+}
+
 // FindMonitors finds monitors.
 func (c *Client) FindMonitors() ([]Monitor, error) {
 	return c.FindMonitorsContext(context.Background())
@@ -352,6 +462,7 @@ func (c *Client) FindMonitors() ([]Monitor, error) {
 
 // FindMonitorsContext finds monitors.
 func (c *Client) FindMonitorsContext(ctx context.Context) ([]Monitor, error) {
+	ctx = withOperationName(ctx, "FindMonitors")
 	data, err := requestGetContext[struct {
 		Monitors []json.RawMessage `json:"monitors"`
 	}](ctx, c, "/api/v0/monitors")
@@ -361,16 +472,12 @@ func (c *Client) FindMonitorsContext(ctx context.Context) ([]Monitor, error) {
 	ms := make([]Monitor, 0, len(data.Monitors))
 	for _, rawmes := range data.Monitors {
 		m, err := decodeMonitor(rawmes)
-		var e *unknownMonitorTypeError
 		if err != nil {
-			if errors.As(err, &e) {
-				break
-			}
 			return nil, err
 		}
 		ms = append(ms, m)
 	}
-	return ms, err
+	return ms, nil
 }
 
 // GetMonitor gets a monitor.
@@ -380,6 +487,7 @@ func (c *Client) GetMonitor(monitorID string) (Monitor, error) {
 
 // GetMonitorContext gets a monitor.
 func (c *Client) GetMonitorContext(ctx context.Context, monitorID string) (Monitor, error) {
+	ctx = withOperationName(ctx, "GetMonitor")
 	data, err := requestGetContext[struct {
 		Monitor json.RawMessage `json:"monitor"`
 	}](ctx, c, fmt.Sprintf("/api/v0/monitors/%s", monitorID))
@@ -400,6 +508,7 @@ func (c *Client) CreateMonitor(param Monitor) (Monitor, error) {
 
 // CreateMonitorContext creates a monitor.
 func (c *Client) CreateMonitorContext(ctx context.Context, param Monitor) (Monitor, error) {
+	ctx = withOperationName(ctx, "CreateMonitor")
 	data, err := requestPostContext[json.RawMessage](ctx, c, "/api/v0/monitors", param)
 	if err != nil {
 		return nil, err
@@ -414,6 +523,7 @@ func (c *Client) UpdateMonitor(monitorID string, param Monitor) (Monitor, error)
 
 // UpdateMonitorContext updates a monitor.
 func (c *Client) UpdateMonitorContext(ctx context.Context, monitorID string, param Monitor) (Monitor, error) {
+	ctx = withOperationName(ctx, "UpdateMonitor")
 	path := fmt.Sprintf("/api/v0/monitors/%s", monitorID)
 	data, err := requestPutContext[json.RawMessage](ctx, c, path, param)
 	if err != nil {
@@ -429,6 +539,7 @@ func (c *Client) DeleteMonitor(monitorID string) (Monitor, error) {
 
 // DeleteMonitorContext updates a monitor.
 func (c *Client) DeleteMonitorContext(ctx context.Context, monitorID string) (Monitor, error) {
+	ctx = withOperationName(ctx, "DeleteMonitor")
 	path := fmt.Sprintf("/api/v0/monitors/%s", monitorID)
 	data, err := requestDeleteContext[json.RawMessage](ctx, c, path)
 	if err != nil {
@@ -437,12 +548,11 @@ func (c *Client) DeleteMonitorContext(ctx context.Context, monitorID string) (Mo
 	return decodeMonitor(*data)
 }
 
-type unknownMonitorTypeError struct {
-	Type string
-}
-
-func (e *unknownMonitorTypeError) Error() string {
-	return fmt.Sprintf("unknown monitor type: %s", e.Type)
+// DecodeMonitor decodes the JSON of a monitor into the type of its "type" field,
+// such as *MonitorHostMetric. Monitors of unknown types are decoded to *MonitorUnknown,
+// and an error is returned if the type is missing or empty.
+func DecodeMonitor(data []byte) (Monitor, error) {
+	return decodeMonitor(data)
 }
 
 // decodeMonitor decodes json.RawMessage and returns monitor.
@@ -455,6 +565,8 @@ func decodeMonitor(mes json.RawMessage) (Monitor, error) {
 	}
 	var m Monitor
 	switch typeData.Type {
+	case "":
+		return nil, errors.New("monitor type is missing")
 	case monitorTypeConnectivity:
 		m = &MonitorConnectivity{}
 	case monitorTypeHostMetric:
@@ -470,7 +582,10 @@ func decodeMonitor(mes json.RawMessage) (Monitor, error) {
 	case monitorTypeQuery:
 		m = &MonitorQuery{}
 	default:
-		return nil, &unknownMonitorTypeError{Type: typeData.Type}
+		if decode, ok := lookupMonitorDecoder(typeData.Type); ok {
+			return decode(mes)
+		}
+		m = &MonitorUnknown{}
 	}
 	if err := json.Unmarshal(mes, m); err != nil {
 		return nil, err
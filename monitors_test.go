@@ -2,8 +2,8 @@ package mackerel
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -829,19 +829,124 @@ func TestDecodeUnknownMonitor(t *testing.T) {
 	json := `{
 		"id"  : "2cSZzK3XfmE",
 		"type": "unknown",
+		"name": "unknown monitor",
 		"unknownField": "unknownValue"
 	}`
 	gotMonitor, err := decodeMonitorReader(strings.NewReader(json))
-	if gotMonitor != nil {
-		t.Errorf("gotMonitor should be nil but: %v", gotMonitor)
+	if err != nil {
+		t.Fatalf("err should be nil but: %v", err)
+	}
+	m, ok := gotMonitor.(*MonitorUnknown)
+	if !ok {
+		t.Fatalf("gotMonitor should be *MonitorUnknown but: %T", gotMonitor)
+	}
+	if m.MonitorID() != "2cSZzK3XfmE" || m.MonitorType() != "unknown" || m.MonitorName() != "unknown monitor" {
+		t.Errorf("unexpected monitor: %+v", m)
+	}
+	b, err := m.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalJSON(string(b), json) {
+		t.Errorf("got %s, want %s", b, json)
 	}
-	if err == nil {
-		t.Errorf("err should be unknownMonitorTypeError but: %v", err)
-	} else {
-		var e *unknownMonitorTypeError
-		if !errors.As(err, &e) {
-			t.Errorf("err should be unknownMonitorTypeError but: %v", err)
+
+	m.ID, m.Name, m.Type = "", "renamed monitor", "other"
+	b, err = m.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"type":"other","name":"renamed monitor","unknownField":"unknownValue"}`; !equalJSON(string(b), want) {
+		t.Errorf("got %s, want %s", b, want)
+	}
+}
+
+func TestDecodeMonitor_missingType(t *testing.T) {
+	for _, data := range []string{`null`, `{}`, `{"type":""}`, `{"type":null,"name":"monitor"}`} {
+		if m, err := DecodeMonitor([]byte(data)); err == nil {
+			t.Errorf("DecodeMonitor(%s) should fail but: %+v", data, m)
+		}
+	}
+}
+
+type monitorNew struct {
+	MonitorUnknown
+	Threshold float64
+}
+
+func TestRegisterMonitorDecoder(t *testing.T) {
+	RegisterMonitorDecoder("new", func(data json.RawMessage) (Monitor, error) {
+		var m monitorNew
+		if err := json.Unmarshal(data, &m.MonitorUnknown); err != nil {
+			return nil, err
+		}
+		var v struct {
+			Threshold float64 `json:"threshold"`
 		}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		m.Threshold = v.Threshold
+		return &m, nil
+	})
+	defer func() {
+		monitorDecodersMu.Lock()
+		delete(monitorDecoders, "new")
+		monitorDecodersMu.Unlock()
+	}()
+
+	gotMonitor, err := decodeMonitorReader(strings.NewReader(`{"id":"2cSZzK3XfmE","type":"new","threshold":1.5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok := gotMonitor.(*monitorNew)
+	if !ok {
+		t.Fatalf("gotMonitor should be *monitorNew but: %T", gotMonitor)
+	}
+	if m.MonitorID() != "2cSZzK3XfmE" || m.Threshold != 1.5 {
+		t.Errorf("unexpected monitor: %+v", m)
+	}
+}
+
+func TestFindMonitors_unknown(t *testing.T) {
+	var updated string
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			res.Write([]byte(`{"monitors":[
+				{"id":"2cSZzK3XfmA","type":"connectivity"},
+				{"id":"2cSZzK3XfmB","type":"unknown","name":"unknown","unknownField":{"a":1}},
+				{"id":"2cSZzK3XfmC","type":"query","name":"query","query":"container.cpu.utilization","warning":null,"critical":null}
+			]}`)) // nolint
+		case http.MethodPut:
+			body, _ := io.ReadAll(req.Body)
+			updated = string(body)
+			res.Write(body) // nolint
+		}
+	}))
+	defer ts.Close()
+
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+	monitors, err := client.FindMonitorsContext(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(monitors) != 3 {
+		t.Fatalf("monitors should have 3 elements but: %d", len(monitors))
+	}
+	m, ok := monitors[1].(*MonitorUnknown)
+	if !ok {
+		t.Fatalf("monitors[1] should be *MonitorUnknown but: %T", monitors[1])
+	}
+	got, err := client.UpdateMonitorContext(t.Context(), m.MonitorID(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalJSON(updated, string(m.Raw)) {
+		t.Errorf("raw JSON should be sent as is but: %s", updated)
+	}
+	if got.MonitorType() != "unknown" {
+		t.Errorf("unexpected monitor: %+v", got)
 	}
 }
 
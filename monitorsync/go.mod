@@ -0,0 +1,14 @@
+module github.com/mackerelio/mackerel-client-go/monitorsync
+
+go 1.25.0
+
+require (
+	github.com/mackerelio/mackerel-client-go v0.0.0-20261016230104-842a7b660033
+	go.yaml.in/yaml/v3 v3.0.5
+)
+
+require github.com/BurntSushi/toml v1.6.0 // indirect
+
+// The root module is replaced to develop both in this repository.
+// Replace directives are ignored in dependents, so they use the version required above.
+replace github.com/mackerelio/mackerel-client-go => ../
@@ -0,0 +1,169 @@
+package monitorsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/mackerelio/mackerel-client-go"
+	"go.yaml.in/yaml/v3"
+)
+
+// Load loads the monitor definitions in the files under dir.
+// See [LoadFS] for the format of the files.
+func Load(dir string) ([]mackerel.Monitor, error) {
+	return LoadFS(os.DirFS(dir))
+}
+
+// LoadFS loads the monitor definitions in the files of fsys, walking it in lexical order.
+//
+// Files with the extension .yaml, .yml or .json are loaded and the others are ignored.
+// A file holds a monitor object in the format of the API, a list of them,
+// or an object with the list as "monitors" like the response of FindMonitors.
+// YAML files may consist of multiple documents.
+// Every monitor is checked by its Validate method.
+func LoadFS(fsys fs.FS) ([]mackerel.Monitor, error) {
+	var monitors []mackerel.Monitor
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		var yamlFormat bool
+		switch path.Ext(p) {
+		case ".yaml", ".yml":
+			yamlFormat = true
+		case ".json":
+		default:
+			return nil
+		}
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		ms, err := parse(b, yamlFormat)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		monitors = append(monitors, ms...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return monitors, nil
+}
+
+func parse(b []byte, yamlFormat bool) ([]mackerel.Monitor, error) {
+	if !yamlFormat {
+		return parseDocument(json.RawMessage(b))
+	}
+	var monitors []mackerel.Monitor
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			if errors.Is(err, io.EOF) {
+				return monitors, nil
+			}
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		ms, err := parseDocument(b)
+		if err != nil {
+			return nil, err
+		}
+		monitors = append(monitors, ms...)
+	}
+}
+
+func parseDocument(b json.RawMessage) ([]mackerel.Monitor, error) {
+	var raws []json.RawMessage
+	switch b = bytes.TrimSpace(b); {
+	case bytes.HasPrefix(b, []byte("[")):
+		if err := json.Unmarshal(b, &raws); err != nil {
+			return nil, err
+		}
+	default:
+		var list struct {
+			Monitors []json.RawMessage `json:"monitors"`
+		}
+		if err := json.Unmarshal(b, &list); err != nil {
+			return nil, err
+		}
+		if list.Monitors != nil {
+			raws = list.Monitors
+		} else {
+			raws = []json.RawMessage{b}
+		}
+	}
+	monitors := make([]mackerel.Monitor, 0, len(raws))
+	for i, raw := range raws {
+		m, err := mackerel.DecodeMonitor(raw)
+		if err != nil {
+			return nil, fmt.Errorf("monitor #%d: %w", i+1, err)
+		}
+		if _, ok := m.(*mackerel.MonitorUnknown); ok {
+			return nil, fmt.Errorf("monitor #%d: unknown monitor type: %q", i+1, m.MonitorType())
+		}
+		if err := m.Validate(); err != nil {
+			return nil, fmt.Errorf("monitor %q: %w", m.MonitorName(), err)
+		}
+		monitors = append(monitors, m)
+	}
+	return monitors, nil
+}
+
+// Key returns the key matching m with its definition: the value of the annotation in the memo
+// such as "monitorsync-id: cpu", or the name of m if the memo does not have it.
+func Key(m mackerel.Monitor, annotation string) string {
+	if v, ok := annotationValue(memo(m), annotation); ok {
+		return v
+	}
+	return m.MonitorName()
+}
+
+func annotationValue(memo, annotation string) (string, bool) {
+	for line := range strings.Lines(memo) {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), annotation+":"); ok {
+			if v = strings.TrimSpace(v); v != "" {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
+func memo(m mackerel.Monitor) string {
+	switch m := m.(type) {
+	case *mackerel.MonitorConnectivity:
+		return m.Memo
+	case *mackerel.MonitorHostMetric:
+		return m.Memo
+	case *mackerel.MonitorServiceMetric:
+		return m.Memo
+	case *mackerel.MonitorExternalHTTP:
+		return m.Memo
+	case *mackerel.MonitorExpression:
+		return m.Memo
+	case *mackerel.MonitorAnomalyDetection:
+		return m.Memo
+	case *mackerel.MonitorQuery:
+		return m.Memo
+	}
+	return ""
+}
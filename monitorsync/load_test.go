@@ -0,0 +1,73 @@
+package monitorsync
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/mackerelio/mackerel-client-go"
+)
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"host.yaml": {Data: []byte(`
+type: host
+name: loadavg5
+metric: loadavg5
+operator: ">"
+warning: 4
+critical: 8
+duration: 3
+---
+type: connectivity
+memo: "monitorsync-id: connectivity"
+`)},
+		"external/list.json": {Data: []byte(`[
+			{"type": "external", "name": "example.com", "url": "https://example.com/", "headers": []}
+		]`)},
+		"query.yml": {Data: []byte(`
+monitors:
+- type: query
+  name: cpu
+  query: container.cpu.utilization
+  operator: ">"
+  critical: 90
+`)},
+		"README.md": {Data: []byte(`# monitors`)},
+	}
+	monitors, err := LoadFS(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(monitors) != 4 {
+		t.Fatalf("4 monitors should be loaded but: %d", len(monitors))
+	}
+	if m, ok := monitors[0].(*mackerel.MonitorExternalHTTP); !ok || m.URL != "https://example.com/" || m.Headers == nil {
+		t.Errorf("unexpected monitor: %#v", monitors[0])
+	}
+	if m, ok := monitors[1].(*mackerel.MonitorHostMetric); !ok || *m.Warning != 4 || m.Critical == nil || m.Duration != 3 {
+		t.Errorf("unexpected monitor: %#v", monitors[1])
+	}
+	if Key(monitors[2], DefaultAnnotation) != "connectivity" {
+		t.Errorf("key should be taken from memo but: %q", Key(monitors[2], DefaultAnnotation))
+	}
+	if Key(monitors[3], DefaultAnnotation) != "cpu" {
+		t.Errorf("key should be the name but: %q", Key(monitors[3], DefaultAnnotation))
+	}
+}
+
+func TestLoadFS_invalid(t *testing.T) {
+	fsys := fstest.MapFS{
+		"host.yaml": {Data: []byte(`{type: host, name: loadavg5, metric: loadavg5, duration: 3}`)},
+	}
+	if _, err := LoadFS(fsys); !errors.Is(err, mackerel.ErrValidation) {
+		t.Error("LoadFS should fail with ErrValidation but: ", err)
+	}
+
+	fsys = fstest.MapFS{
+		"new.json": {Data: []byte(`{"type": "new", "name": "new"}`)},
+	}
+	if _, err := LoadFS(fsys); err == nil {
+		t.Error("LoadFS should fail for unknown monitor types")
+	}
+}
@@ -0,0 +1,286 @@
+// Package monitorsync manages Mackerel monitors as code.
+//
+// It loads monitor definitions from YAML or JSON files, computes a plan to make the monitors
+// of the organization match them, and applies the plan:
+//
+//	desired, err := monitorsync.Load("monitors")
+//	plan, err := monitorsync.NewPlan(ctx, client, desired, nil)
+//	plan.WriteTo(os.Stdout)
+//	results, err := plan.Apply(ctx, client, &monitorsync.ApplyOptions{Concurrency: 4})
+//
+// Monitors are matched with their definitions by [Key].
+package monitorsync
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mackerelio/mackerel-client-go"
+)
+
+// DefaultAnnotation is the default annotation in memos that identifies monitors.
+const DefaultAnnotation = "monitorsync-id"
+
+// Action is what a [Change] does to a monitor.
+type Action int
+
+// Actions, in the order they are applied.
+const (
+	ActionCreate Action = iota
+	ActionUpdate
+	ActionDelete
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionCreate:
+		return "create"
+	case ActionUpdate:
+		return "update"
+	case ActionDelete:
+		return "delete"
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// Change is a change to a monitor in a [Plan].
+type Change struct {
+	Action Action
+	// Key is the key of the monitor returned by [Key].
+	Key string
+	// Current is the monitor in the organization, nil for ActionCreate.
+	Current mackerel.Monitor
+	// Desired is the definition of the monitor, nil for ActionDelete.
+	Desired mackerel.Monitor
+	// Diffs are the differences of the fields for ActionUpdate.
+	Diffs []mackerel.MonitorFieldDiff
+}
+
+// Plan is the ordered list of changes: creations, updates and then deletions, each sorted by key.
+type Plan struct {
+	Changes []*Change
+	// Ambiguous are the monitors in the organization sharing their keys with others, grouped by the keys.
+	// They are left as they are, and their definitions are not applied.
+	Ambiguous map[string][]mackerel.Monitor
+}
+
+// Options configures [NewPlan].
+type Options struct {
+	// Annotation is the annotation in memos used by [Key]. The default is DefaultAnnotation.
+	Annotation string
+	// Prune deletes the monitors without definitions.
+	// Without it, such monitors are left as they are.
+	Prune bool
+}
+
+// NewPlan returns the plan to make the monitors found by client match desired.
+// opts may be nil.
+func NewPlan(ctx context.Context, client *mackerel.Client, desired []mackerel.Monitor, opts *Options) (*Plan, error) {
+	current, err := client.FindMonitorsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Compute(current, desired, opts)
+}
+
+// Compute returns the plan to make current match desired.
+// It returns an error if keys of desired monitors are duplicated.
+// Current monitors of duplicated keys, such as those of the same name without annotations,
+// are reported as Ambiguous of the plan.
+func Compute(current, desired []mackerel.Monitor, opts *Options) (*Plan, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	annotation := cmp.Or(opts.Annotation, DefaultAnnotation)
+	curByKey, ambiguous := currentByKey(current, annotation)
+	desByKey, err := byKey(desired, annotation)
+	if err != nil {
+		return nil, fmt.Errorf("desired monitors: %w", err)
+	}
+
+	plan := Plan{Ambiguous: ambiguous}
+	for key, d := range desByKey {
+		if _, ok := ambiguous[key]; ok {
+			continue
+		}
+		c, ok := curByKey[key]
+		if !ok {
+			plan.Changes = append(plan.Changes, &Change{Action: ActionCreate, Key: key, Desired: d})
+			continue
+		}
+		if c.MonitorType() != d.MonitorType() {
+			return nil, fmt.Errorf("monitor %q: type cannot be changed from %s to %s", key, c.MonitorType(), d.MonitorType())
+		}
+		if diffs := mackerel.DiffMonitor(c, d); len(diffs) > 0 {
+			plan.Changes = append(plan.Changes, &Change{Action: ActionUpdate, Key: key, Current: c, Desired: d, Diffs: diffs})
+		}
+	}
+	if opts.Prune {
+		for key, c := range curByKey {
+			if _, ok := desByKey[key]; !ok {
+				plan.Changes = append(plan.Changes, &Change{Action: ActionDelete, Key: key, Current: c})
+			}
+		}
+	}
+	slices.SortFunc(plan.Changes, func(a, b *Change) int {
+		return cmp.Or(cmp.Compare(a.Action, b.Action), strings.Compare(a.Key, b.Key))
+	})
+	return &plan, nil
+}
+
+func byKey(monitors []mackerel.Monitor, annotation string) (map[string]mackerel.Monitor, error) {
+	m := make(map[string]mackerel.Monitor, len(monitors))
+	for _, monitor := range monitors {
+		key := Key(monitor, annotation)
+		if _, ok := m[key]; ok {
+			return nil, fmt.Errorf("duplicate monitor key: %q", key)
+		}
+		m[key] = monitor
+	}
+	return m, nil
+}
+
+// currentByKey is like byKey, but it leaves out the monitors of duplicated keys
+// and returns them grouped by the keys instead of failing.
+func currentByKey(monitors []mackerel.Monitor, annotation string) (map[string]mackerel.Monitor, map[string][]mackerel.Monitor) {
+	groups := make(map[string][]mackerel.Monitor, len(monitors))
+	for _, monitor := range monitors {
+		key := Key(monitor, annotation)
+		groups[key] = append(groups[key], monitor)
+	}
+	m := make(map[string]mackerel.Monitor, len(groups))
+	var ambiguous map[string][]mackerel.Monitor
+	for key, g := range groups {
+		if len(g) == 1 {
+			m[key] = g[0]
+			continue
+		}
+		if ambiguous == nil {
+			ambiguous = make(map[string][]mackerel.Monitor)
+		}
+		ambiguous[key] = g
+	}
+	return m, ambiguous
+}
+
+// WriteTo writes the plan in a human readable format.
+func (p *Plan) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	if len(p.Changes) == 0 {
+		b.WriteString("No changes.\n")
+	}
+	for _, c := range p.Changes {
+		switch c.Action {
+		case ActionCreate:
+			fmt.Fprintf(&b, "+ create %s %q\n", c.Desired.MonitorType(), c.Key)
+		case ActionUpdate:
+			fmt.Fprintf(&b, "~ update %s %q (%s)\n", c.Current.MonitorType(), c.Key, c.Current.MonitorID())
+			for _, d := range c.Diffs {
+				fmt.Fprintf(&b, "    %s: %s => %s\n", d.Path, formatValue(d.Old), formatValue(d.New))
+			}
+		case ActionDelete:
+			fmt.Fprintf(&b, "- delete %s %q (%s)\n", c.Current.MonitorType(), c.Key, c.Current.MonitorID())
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(p.Ambiguous)) {
+		ids := make([]string, len(p.Ambiguous[key]))
+		for i, m := range p.Ambiguous[key] {
+			ids[i] = m.MonitorID()
+		}
+		fmt.Fprintf(&b, "! skip ambiguous %q (%s)\n", key, strings.Join(ids, ", "))
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func formatValue(v any) string {
+	if v == nil {
+		return "(none)"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// ApplyOptions configures [Plan.Apply].
+type ApplyOptions struct {
+	// Concurrency is the maximum number of changes applied at once. The default is 1.
+	Concurrency int
+}
+
+// Result is the result of applying a [Change].
+type Result struct {
+	Change *Change
+	// Monitor is the monitor returned by the API, nil for ActionDelete or on failure.
+	Monitor mackerel.Monitor
+	Err     error
+	// Skipped reports whether the change is not applied because an earlier change failed.
+	Skipped bool
+}
+
+// Apply applies the changes of the plan with client, all the creations and updates before deletions.
+// If any of the creations and updates fails, the deletions are skipped.
+// It returns the results in the order of the changes, and the errors of the failed changes joined.
+// opts may be nil.
+func (p *Plan) Apply(ctx context.Context, client *mackerel.Client, opts *ApplyOptions) ([]*Result, error) {
+	concurrency := 1
+	if opts != nil && opts.Concurrency > 0 {
+		concurrency = opts.Concurrency
+	}
+	results := make([]*Result, len(p.Changes))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var failed bool
+	for i, c := range p.Changes {
+		// Deletions wait for the others so that monitors are not missing on failures.
+		if c.Action == ActionDelete && (i == 0 || p.Changes[i-1].Action != ActionDelete) {
+			wg.Wait()
+			failed = slices.ContainsFunc(results[:i], func(r *Result) bool { return r.Err != nil })
+		}
+		if c.Action == ActionDelete && failed {
+			results[i] = &Result{Change: c, Skipped: true}
+			continue
+		}
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			results[i] = apply(ctx, client, c)
+		})
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s monitor %q: %w", r.Change.Action, r.Change.Key, r.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+func apply(ctx context.Context, client *mackerel.Client, c *Change) *Result {
+	r := &Result{Change: c}
+	if err := ctx.Err(); err != nil {
+		r.Err = err
+		return r
+	}
+	switch c.Action {
+	case ActionCreate:
+		r.Monitor, r.Err = client.CreateMonitorContext(ctx, c.Desired)
+	case ActionUpdate:
+		r.Monitor, r.Err = client.UpdateMonitorContext(ctx, c.Current.MonitorID(), c.Desired)
+	case ActionDelete:
+		_, r.Err = client.DeleteMonitorContext(ctx, c.Current.MonitorID())
+	}
+	return r
+}
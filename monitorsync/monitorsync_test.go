@@ -0,0 +1,159 @@
+package monitorsync
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mackerelio/mackerel-client-go"
+	"github.com/mackerelio/mackerel-client-go/mackereltest"
+)
+
+func pfloat64(v float64) *float64 { return &v }
+
+func TestPlan(t *testing.T) {
+	s := mackereltest.NewServer()
+	defer s.Close()
+	client, err := mackerel.New("dummy-key", mackerel.WithBaseURL(s.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := t.Context()
+	for _, m := range []mackerel.Monitor{
+		&mackerel.MonitorHostMetric{
+			Type: "host", Name: "loadavg5", Metric: "loadavg5", Operator: ">",
+			Warning: pfloat64(4), Critical: pfloat64(8), Duration: 3,
+		},
+		&mackerel.MonitorExternalHTTP{
+			Type: "external", Name: "example.com", URL: "https://example.com/",
+			Headers: []mackerel.HeaderField{{Name: "Cache-Control", Value: "no-cache"}},
+		},
+		&mackerel.MonitorConnectivity{Type: "connectivity", Memo: "managed\nmonitorsync-id: connectivity"},
+		&mackerel.MonitorQuery{Type: "query", Name: "obsolete", Query: "cpu", Operator: ">", Critical: pfloat64(90)},
+	} {
+		if _, err := client.CreateMonitorContext(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	desired := []mackerel.Monitor{
+		&mackerel.MonitorHostMetric{
+			Type: "host", Name: "loadavg5", Metric: "loadavg5", Operator: ">",
+			Warning: nil, Critical: pfloat64(10), Duration: 3,
+		},
+		// Headers are left as they are.
+		&mackerel.MonitorExternalHTTP{Type: "external", Name: "example.com", URL: "https://example.com/"},
+		&mackerel.MonitorConnectivity{Type: "connectivity", Name: "renamed", Memo: "managed\nmonitorsync-id: connectivity"},
+		&mackerel.MonitorServiceMetric{
+			Type: "service", Name: "4xx", Service: "My-Service", Metric: "custom.4xx",
+			Operator: ">", Critical: pfloat64(100), Duration: 1,
+		},
+	}
+	plan, err := NewPlan(ctx, client, desired, &Options{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if _, err := plan.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]string{}
+	for _, c := range plan.Changes {
+		if c.Current != nil {
+			ids[c.Key] = c.Current.MonitorID()
+		}
+	}
+	want := `+ create service "4xx"
+~ update connectivity "connectivity" (` + ids["connectivity"] + `)
+    name: "" => "renamed"
+~ update host "loadavg5" (` + ids["loadavg5"] + `)
+    warning: 4 => (none)
+    critical: 8 => 10
+- delete query "obsolete" (` + ids["obsolete"] + `)
+`
+	if got := b.String(); got != want {
+		t.Errorf("plan should be:\n%s\nbut:\n%s", want, got)
+	}
+
+	results, err := plan.Apply(ctx, client, &ApplyOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 || results[0].Monitor == nil || results[0].Monitor.MonitorID() == "" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	plan, err = NewPlan(ctx, client, desired, &Options{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 0 {
+		t.Errorf("plan should be empty after applied but: %+v", plan.Changes)
+	}
+}
+
+func TestCompute_duplicateKey(t *testing.T) {
+	desired := []mackerel.Monitor{
+		&mackerel.MonitorConnectivity{Type: "connectivity", Name: "connectivity"},
+		&mackerel.MonitorConnectivity{Type: "connectivity", Name: "connectivity"},
+	}
+	if _, err := Compute(nil, desired, nil); err == nil {
+		t.Error("Compute should fail for duplicate keys")
+	}
+}
+
+func TestCompute_ambiguousKey(t *testing.T) {
+	current := []mackerel.Monitor{
+		&mackerel.MonitorConnectivity{ID: "2cSZzK3XfmA", Type: "connectivity", Name: "connectivity"},
+		&mackerel.MonitorConnectivity{ID: "2cSZzK3XfmB", Type: "connectivity", Name: "connectivity"},
+		&mackerel.MonitorConnectivity{ID: "2cSZzK3XfmC", Type: "connectivity", Name: "other"},
+	}
+	desired := []mackerel.Monitor{
+		&mackerel.MonitorConnectivity{Type: "connectivity", Name: "connectivity", Memo: "updated"},
+	}
+	plan, err := Compute(current, desired, &Options{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if _, err := plan.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `- delete connectivity "other" (2cSZzK3XfmC)
+! skip ambiguous "connectivity" (2cSZzK3XfmA, 2cSZzK3XfmB)
+`
+	if got := b.String(); got != want {
+		t.Errorf("plan should be:\n%s\nbut:\n%s", want, got)
+	}
+}
+
+func TestApply_skipDeletions(t *testing.T) {
+	s := mackereltest.NewServer()
+	defer s.Close()
+	client, err := mackerel.New("dummy-key", mackerel.WithBaseURL(s.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := t.Context()
+	obsolete, err := client.CreateMonitorContext(ctx, &mackerel.MonitorConnectivity{Type: "connectivity", Name: "obsolete"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := &Plan{Changes: []*Change{
+		{
+			Action:  ActionUpdate,
+			Key:     "missing",
+			Current: &mackerel.MonitorConnectivity{ID: "2cSZzK3XfmZ", Type: "connectivity", Name: "missing"},
+			Desired: &mackerel.MonitorConnectivity{Type: "connectivity", Name: "missing"},
+		},
+		{Action: ActionDelete, Key: "obsolete", Current: obsolete},
+	}}
+	results, err := plan.Apply(ctx, client, nil)
+	if err == nil {
+		t.Fatal("Apply should fail for the missing monitor")
+	}
+	if len(results) != 2 || results[0].Err == nil || !results[1].Skipped || results[1].Err != nil {
+		t.Errorf("the deletion should be skipped but: %+v", results)
+	}
+	if _, err := client.GetMonitorContext(ctx, obsolete.MonitorID()); err != nil {
+		t.Errorf("the monitor should not be deleted: %v", err)
+	}
+}
@@ -57,6 +57,7 @@ func (c *Client) FindNotificationGroups() ([]*NotificationGroup, error) {
 
 // FindNotificationGroupsContext finds notification groups.
 func (c *Client) FindNotificationGroupsContext(ctx context.Context) ([]*NotificationGroup, error) {
+	ctx = withOperationName(ctx, "FindNotificationGroups")
 	data, err := requestGetContext[struct {
 		NotificationGroups []*NotificationGroup `json:"notificationGroups"`
 	}](ctx, c, "/api/v0/notification-groups")
@@ -73,6 +74,7 @@ func (c *Client) CreateNotificationGroup(param *NotificationGroup) (*Notificatio
 
 // CreateNotificationGroupContext creates a notification group.
 func (c *Client) CreateNotificationGroupContext(ctx context.Context, param *NotificationGroup) (*NotificationGroup, error) {
+	ctx = withOperationName(ctx, "CreateNotificationGroup")
 	return requestPostContext[NotificationGroup](ctx, c, "/api/v0/notification-groups", param)
 }
 
@@ -83,6 +85,7 @@ func (c *Client) UpdateNotificationGroup(id string, param *NotificationGroup) (*
 
 // UpdateNotificationGroupContext updates a notification group.
 func (c *Client) UpdateNotificationGroupContext(ctx context.Context, id string, param *NotificationGroup) (*NotificationGroup, error) {
+	ctx = withOperationName(ctx, "UpdateNotificationGroup")
 	path := fmt.Sprintf("/api/v0/notification-groups/%s", id)
 	return requestPutContext[NotificationGroup](ctx, c, path, param)
 }
@@ -94,6 +97,7 @@ func (c *Client) DeleteNotificationGroup(id string) (*NotificationGroup, error)
 
 // DeleteNotificationGroupContext deletes a notification group.
 func (c *Client) DeleteNotificationGroupContext(ctx context.Context, id string) (*NotificationGroup, error) {
+	ctx = withOperationName(ctx, "DeleteNotificationGroup")
 	path := fmt.Sprintf("/api/v0/notification-groups/%s", id)
 	return requestDeleteContext[NotificationGroup](ctx, c, path)
 }
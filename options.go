@@ -0,0 +1,274 @@
+package mackerel
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// Option configures a Client created by [New].
+type Option func(*clientOptions) error
+
+type clientOptions struct {
+	baseURL           string
+	verbose           bool
+	userAgentSuffix   string
+	headers           http.Header
+	httpClient        *http.Client
+	timeout           time.Duration
+	transport         http.RoundTripper
+	proxy             *url.URL
+	tlsConfig         *tls.Config
+	logger            *log.Logger
+	prioritizedLogger PrioritizedLogger
+	slogLogger        *slog.Logger
+	logBodyLimit      int
+	retryPolicy       *RetryPolicy
+	rateLimiter       RateLimiter
+	middlewares       []Middleware
+	dryRun            *DryRun
+}
+
+// New returns a new Client configured by opts.
+// Unlike [NewClientWithOptions], the configuration is validated up front.
+//
+// The fields of the returned Client are still exported and may be changed,
+// but they must not be changed while the client is used by other goroutines.
+func New(apiKey string, opts ...Option) (*Client, error) {
+	if apiKey == "" {
+		return nil, errors.New("API key is empty")
+	}
+	o := clientOptions{baseURL: defaultBaseURL, headers: http.Header{}}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+	u, err := parseBaseURL(o.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := o.newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	userAgent := defaultUserAgent
+	if o.userAgentSuffix != "" {
+		userAgent += " " + o.userAgentSuffix
+	}
+	return &Client{
+		BaseURL:           u,
+		APIKey:            apiKey,
+		Verbose:           o.verbose,
+		UserAgent:         userAgent,
+		AdditionalHeaders: o.headers,
+		HTTPClient:        httpClient,
+		RetryPolicy:       o.retryPolicy,
+		RateLimiter:       o.rateLimiter,
+		Middlewares:       o.middlewares,
+		DryRun:            o.dryRun,
+		Logger:            o.logger,
+		PrioritizedLogger: o.prioritizedLogger,
+		SlogLogger:        o.slogLogger,
+		LogBodyLimit:      o.logBodyLimit,
+	}, nil
+}
+
+func parseBaseURL(rawurl string) (*url.URL, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("base URL must be http or https: %s", rawurl)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("base URL must have a host: %s", rawurl)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("base URL must not have a query or fragment: %s", rawurl)
+	}
+	return u, nil
+}
+
+func (o *clientOptions) newHTTPClient() (*http.Client, error) {
+	c := &http.Client{Timeout: apiRequestTimeout}
+	if o.httpClient != nil {
+		hc := *o.httpClient
+		c = &hc
+	}
+	if o.timeout > 0 {
+		c.Timeout = o.timeout
+	}
+	if o.transport != nil {
+		c.Transport = o.transport
+	}
+	if o.proxy == nil && o.tlsConfig == nil {
+		return c, nil
+	}
+	base := c.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t, ok := base.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("proxy and TLS config require *http.Transport but the transport is %T", base)
+	}
+	t = t.Clone()
+	if o.proxy != nil {
+		t.Proxy = http.ProxyURL(o.proxy)
+	}
+	if o.tlsConfig != nil {
+		t.TLSClientConfig = o.tlsConfig.Clone()
+	}
+	c.Transport = t
+	return c, nil
+}
+
+// WithBaseURL sets the base URL of the API. It must be an absolute http or https URL.
+func WithBaseURL(rawurl string) Option {
+	return func(o *clientOptions) error {
+		o.baseURL = rawurl
+		return nil
+	}
+}
+
+// WithVerbose enables dumping requests and responses to the loggers.
+func WithVerbose(verbose bool) Option {
+	return func(o *clientOptions) error {
+		o.verbose = verbose
+		return nil
+	}
+}
+
+// WithUserAgentSuffix appends suffix to the User-Agent header, such as "my-tool/1.0".
+func WithUserAgentSuffix(suffix string) Option {
+	return func(o *clientOptions) error {
+		o.userAgentSuffix = suffix
+		return nil
+	}
+}
+
+// WithHeader adds a header sent with every request.
+func WithHeader(key, value string) Option {
+	return func(o *clientOptions) error {
+		o.headers.Add(key, value)
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client. The client is copied,
+// and WithTimeout, WithTransport, WithProxy and WithTLSConfig apply to the copy.
+// The copy shares the Transport of c unless WithTransport, WithProxy or WithTLSConfig replaces it.
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *clientOptions) error {
+		if c == nil {
+			return errors.New("HTTP client is nil")
+		}
+		o.httpClient = c
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of each request. The default is 30 seconds.
+func WithTimeout(d time.Duration) Option {
+	return func(o *clientOptions) error {
+		if d <= 0 {
+			return fmt.Errorf("timeout must be positive: %s", d)
+		}
+		o.timeout = d
+		return nil
+	}
+}
+
+// WithTransport sets the transport of the HTTP client.
+func WithTransport(t http.RoundTripper) Option {
+	return func(o *clientOptions) error {
+		o.transport = t
+		return nil
+	}
+}
+
+// WithProxy sets the URL of the proxy, instead of the proxy of the environment.
+// It requires the transport to be [*http.Transport].
+func WithProxy(rawurl string) Option {
+	return func(o *clientOptions) error {
+		u, err := url.Parse(rawurl)
+		if err != nil {
+			return err
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid proxy URL: %s", rawurl)
+		}
+		o.proxy = u
+		return nil
+	}
+}
+
+// WithTLSConfig sets the TLS configuration. It requires the transport to be [*http.Transport].
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *clientOptions) error {
+		o.tlsConfig = config
+		return nil
+	}
+}
+
+// WithLogger sets Logger of the client.
+func WithLogger(logger *log.Logger) Option {
+	return func(o *clientOptions) error {
+		o.logger = logger
+		return nil
+	}
+}
+
+// WithPrioritizedLogger sets PrioritizedLogger of the client.
+func WithPrioritizedLogger(logger PrioritizedLogger) Option {
+	return func(o *clientOptions) error {
+		o.prioritizedLogger = logger
+		return nil
+	}
+}
+
+// WithSlogLogger sets SlogLogger of the client.
+func WithSlogLogger(logger *slog.Logger) Option {
+	return func(o *clientOptions) error {
+		o.slogLogger = logger
+		return nil
+	}
+}
+
+// WithLogBodyLimit sets LogBodyLimit of the client.
+func WithLogBodyLimit(n int) Option {
+	return func(o *clientOptions) error {
+		o.logBodyLimit = n
+		return nil
+	}
+}
+
+// WithRetryPolicy sets the retry policy. The policy is copied.
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(o *clientOptions) error {
+		if p == nil {
+			o.retryPolicy = nil
+			return nil
+		}
+		policy := *p
+		policy.RetryableStatusCodes = slices.Clone(p.RetryableStatusCodes)
+		o.retryPolicy = &policy
+		return nil
+	}
+}
+
+// WithRateLimiter sets the rate limiter.
+func WithRateLimiter(l RateLimiter) Option {
+	return func(o *clientOptions) error {
+		o.rateLimiter = l
+		return nil
+	}
+}
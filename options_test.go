@@ -0,0 +1,102 @@
+package mackerel
+
+import (
+	"crypto/tls"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	var userAgent, header string
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		userAgent = req.Header.Get("User-Agent")
+		header = req.Header.Get("X-Custom")
+		res.Write([]byte(`{"name":"my-org"}`)) // nolint
+	}))
+	defer ts.Close()
+
+	policy := &RetryPolicy{MaxAttempts: 2}
+	client, err := New("dummy-key",
+		WithBaseURL(ts.URL),
+		WithUserAgentSuffix("my-tool/1.0"),
+		WithHeader("X-Custom", "value"),
+		WithTimeout(5*time.Second),
+		WithRetryPolicy(policy),
+		WithLogger(log.Default()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy.MaxAttempts = 10
+	if client.RetryPolicy.MaxAttempts != 2 {
+		t.Error("retry policy should be copied but: ", client.RetryPolicy.MaxAttempts)
+	}
+	if client.HTTPClient.Timeout != 5*time.Second {
+		t.Error("timeout should be 5s but: ", client.HTTPClient.Timeout)
+	}
+	if _, err := client.GetOrgContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if userAgent != "mackerel-client-go my-tool/1.0" {
+		t.Error("User-Agent should have the suffix but: ", userAgent)
+	}
+	if header != "value" {
+		t.Error("X-Custom header should be sent but: ", header)
+	}
+}
+
+func TestNew_Transport(t *testing.T) {
+	proxy := "http://proxy.example.com:8080"
+	client, err := New("dummy-key", WithProxy(proxy), WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS13}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport, ok := client.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("transport should be *http.Transport but: %T", client.HTTPClient.Transport)
+	}
+	if transport.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Error("TLS config should be set but: ", transport.TLSClientConfig)
+	}
+	u, err := transport.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "api.mackerelio.com"}})
+	if err != nil || u.String() != proxy {
+		t.Error("proxy should be set but: ", u, err)
+	}
+	if c := http.DefaultTransport.(*http.Transport).TLSClientConfig; c != nil && c.MinVersion == tls.VersionTLS13 {
+		t.Error("default transport should not be modified")
+	}
+
+	type roundTripper struct{ http.RoundTripper }
+	if _, err := New("dummy-key", WithTransport(roundTripper{}), WithProxy(proxy)); err == nil {
+		t.Error("proxy with a custom transport should fail")
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		apiKey string
+		opts   []Option
+		want   string
+	}{
+		{"empty API key", "", nil, "API key"},
+		{"relative base URL", "dummy-key", []Option{WithBaseURL("/api")}, "http or https"},
+		{"base URL without host", "dummy-key", []Option{WithBaseURL("https://")}, "host"},
+		{"base URL with query", "dummy-key", []Option{WithBaseURL("https://api.example.com/?a=b")}, "query"},
+		{"invalid proxy", "dummy-key", []Option{WithProxy("proxy.example.com")}, "proxy"},
+		{"negative timeout", "dummy-key", []Option{WithTimeout(-time.Second)}, "timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.apiKey, tt.opts...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error should contain %q but: %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -15,5 +15,6 @@ func (c *Client) GetOrg() (*Org, error) {
 
 // GetOrgContext gets the org.
 func (c *Client) GetOrgContext(ctx context.Context) (*Org, error) {
+	ctx = withOperationName(ctx, "GetOrg")
 	return requestGetContext[Org](ctx, c, "/api/v0/org")
 }
@@ -0,0 +1,157 @@
+// Package otelmackerel instruments [mackerel.Client] with OpenTelemetry.
+//
+// It records a span and metrics for each API operation and propagates the trace context via headers:
+//
+//	client, err := mackerel.New(apiKey, mackerel.WithMiddleware(otelmackerel.Middleware()))
+//
+// The instrumentation is opt-in: clients without the middleware do not depend on OpenTelemetry at all.
+package otelmackerel
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mackerelio/mackerel-client-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ScopeName is the instrumentation scope name.
+	ScopeName = "github.com/mackerelio/mackerel-client-go/otelmackerel"
+
+	// OperationKey is the attribute key of the name of the Client method, such as "FindHosts".
+	OperationKey = attribute.Key("mackerel.operation")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagators    propagation.TextMapPropagator
+}
+
+// Option configures [Middleware].
+type Option func(*config)
+
+// WithTracerProvider sets the tracer provider. The default is the global one.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) { c.tracerProvider = tp }
+}
+
+// WithMeterProvider sets the meter provider. The default is the global one.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) { c.meterProvider = mp }
+}
+
+// WithPropagators sets the propagators to inject the trace context into requests.
+// The default is the global one.
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) { c.propagators = p }
+}
+
+// Middleware returns a middleware that records a span, the duration and the errors of each API operation.
+//
+// Spans are named after the operation and have the HTTP method, the route template,
+// the response status code and the number of retries as attributes.
+// Retries are included in the span and the duration since they happen inside the operation.
+func Middleware(opts ...Option) mackerel.Middleware {
+	c := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagators:    otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	tracer := c.tracerProvider.Tracer(ScopeName)
+	meter := c.meterProvider.Meter(ScopeName)
+	duration, err := meter.Float64Histogram("http.client.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of Mackerel API operations including retries."))
+	if err != nil {
+		otel.Handle(err)
+	}
+	errorCount, err := meter.Int64Counter("mackerel.client.request.errors",
+		metric.WithUnit("{error}"),
+		metric.WithDescription("Number of Mackerel API operations that failed."))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(next mackerel.Handler) mackerel.Handler {
+		return func(op *mackerel.Operation, req *http.Request) (*http.Response, error) {
+			route := op.Route()
+			attrs := []attribute.KeyValue{
+				OperationKey.String(op.Name),
+				semconv.HTTPRequestMethodKey.String(op.Method),
+				semconv.ServerAddress(req.URL.Hostname()),
+			}
+			if route != "" {
+				attrs = append(attrs, semconv.HTTPRoute(route))
+			}
+			if port, err := strconv.Atoi(req.URL.Port()); err == nil {
+				attrs = append(attrs, semconv.ServerPort(port))
+			}
+			ctx, span := tracer.Start(req.Context(), op.Name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...))
+			defer span.End()
+
+			req = req.WithContext(ctx)
+			c.propagators.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			start := time.Now()
+			resp, err := next(op, req)
+			elapsed := time.Since(start)
+
+			var result []attribute.KeyValue
+			if status := statusCode(resp, err); status != 0 {
+				result = append(result, semconv.HTTPResponseStatusCode(status))
+			}
+			if op.Attempts > 1 {
+				span.SetAttributes(semconv.HTTPRequestResendCount(op.Attempts - 1))
+			}
+			if err != nil {
+				result = append(result, errorType(err))
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.SetAttributes(result...)
+
+			set := metric.WithAttributes(append(attrs, result...)...)
+			if duration != nil {
+				duration.Record(ctx, elapsed.Seconds(), set)
+			}
+			if err != nil && errorCount != nil {
+				errorCount.Add(ctx, 1, set)
+			}
+			return resp, err
+		}
+	}
+}
+
+func statusCode(resp *http.Response, err error) int {
+	if resp != nil {
+		return resp.StatusCode
+	}
+	var apiErr *mackerel.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+func errorType(err error) attribute.KeyValue {
+	var apiErr *mackerel.APIError
+	if errors.As(err, &apiErr) {
+		return semconv.ErrorTypeKey.String(strconv.Itoa(apiErr.StatusCode))
+	}
+	return semconv.ErrorType(err)
+}
@@ -0,0 +1,105 @@
+package otelmackerel
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mackerelio/mackerel-client-go"
+	"github.com/mackerelio/mackerel-client-go/mackereltest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func attr(attrs []attribute.KeyValue, key string) attribute.Value {
+	for _, kv := range attrs {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMiddleware(t *testing.T) {
+	s := mackereltest.NewServer()
+	defer s.Close()
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	client, err := mackerel.New("dummy-key",
+		mackerel.WithBaseURL(s.URL),
+		mackerel.WithRetryPolicy(&mackerel.RetryPolicy{BaseBackoff: time.Millisecond}),
+		mackerel.WithMiddleware(Middleware(
+			WithTracerProvider(tp),
+			WithMeterProvider(mp),
+			WithPropagators(propagation.TraceContext{}),
+		)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.InjectFault(mackereltest.Fault{Path: "/api/v0/hosts/", StatusCode: http.StatusServiceUnavailable, Times: 1})
+	id := s.AddHost(&mackerel.Host{Name: "web01", Status: mackerel.HostStatusWorking})
+	if _, err := client.FindHostContext(t.Context(), id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.FindHostContext(t.Context(), "unknown"); !errors.Is(err, mackerel.ErrNotFound) {
+		t.Fatal("unknown host should not be found but: ", err)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 2 {
+		t.Fatal("2 spans should be recorded but: ", len(ended))
+	}
+	span := ended[0]
+	if span.Name() != "FindHost" {
+		t.Error("span name should be FindHost but: ", span.Name())
+	}
+	attrs := span.Attributes()
+	if v := attr(attrs, "http.route").AsString(); v != "/api/v0/hosts/{hostId}" {
+		t.Error("http.route should be the route template but: ", v)
+	}
+	if v := attr(attrs, "http.response.status_code").AsInt64(); v != 200 {
+		t.Error("http.response.status_code should be 200 but: ", v)
+	}
+	if v := attr(attrs, "http.request.resend_count").AsInt64(); v != 1 {
+		t.Error("http.request.resend_count should be 1 but: ", v)
+	}
+	if span.Status().Code != codes.Unset {
+		t.Error("span status should be unset but: ", span.Status())
+	}
+	if ended[1].Status().Code != codes.Error || attr(ended[1].Attributes(), "http.response.status_code").AsInt64() != 404 {
+		t.Error("span of the failed operation should be an error of 404 but: ", ended[1].Status(), ended[1].Attributes())
+	}
+
+	reqs := s.RequestsTo(http.MethodGet, "/api/v0/hosts/"+id)
+	if len(reqs) == 0 || reqs[len(reqs)-1].Header.Get("Traceparent") == "" {
+		t.Error("trace context should be propagated")
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	if h, ok := metrics["http.client.request.duration"].(metricdata.Histogram[float64]); !ok || len(h.DataPoints) != 2 {
+		t.Error("duration should be recorded for each status but: ", metrics["http.client.request.duration"])
+	}
+	if c, ok := metrics["mackerel.client.request.errors"].(metricdata.Sum[int64]); !ok || len(c.DataPoints) != 1 || c.DataPoints[0].Value != 1 {
+		t.Error("an error should be counted but: ", metrics["mackerel.client.request.errors"])
+	}
+}
@@ -0,0 +1,120 @@
+package mackerel
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// PageOptions configures iterators that fetch subsequent pages from the API.
+type PageOptions struct {
+	// PageSize is the number of items requested per page.
+	// When zero, the size of the API default or of the params passed to the iterator is used.
+	PageSize int
+	// MaxItems stops the iteration after that many items are yielded. Zero means unlimited.
+	MaxItems int
+	// RetryPolicy controls fetching a page again after a retryable error, such as a connection error
+	// or a status of RetryableStatusCodes. The other errors, such as 404, stop the iteration.
+	// When nil, [DefaultRetryPolicy] is used unless the client has its own RetryPolicy,
+	// which retries the requests of the pages already, and then pages are not fetched again.
+	RetryPolicy *RetryPolicy
+}
+
+func (o *PageOptions) pageSize() int {
+	if o == nil {
+		return 0
+	}
+	return o.PageSize
+}
+
+// firstPage returns the page number to start page-number pagination from.
+func firstPage(page *int) int {
+	if page == nil {
+		return 1
+	}
+	return *page
+}
+
+// pageRetryPolicy returns the policy to fetch pages again, or nil if they are not fetched again.
+// client may be nil.
+func (o *PageOptions) pageRetryPolicy(client *Client) *RetryPolicy {
+	switch {
+	case o != nil && o.RetryPolicy != nil:
+		return o.RetryPolicy
+	case client != nil && client.RetryPolicy != nil:
+		return nil
+	}
+	return DefaultRetryPolicy()
+}
+
+// isRetryablePageError reports whether p allows fetching a page again after err.
+func (p *RetryPolicy) isRetryablePageError(err error) bool {
+	if p == nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return p.isRetryableStatus(apiErr.StatusCode)
+	}
+	return p.isRetryableError(err)
+}
+
+// paginate returns an iterator over items that fetch returns page by page with client, which may be nil.
+// fetch receives the cursor of the page, which is first on the first call,
+// and returns the items, the cursor of the next page and whether the next page exists.
+//
+// Errors returned from fetch are reported to the consumer via the second parameter of the iterator.
+// If the error is retryable and the consumer continues, the same page is fetched again
+// after a backoff of the retry policy of opts. The iteration stops on the other errors,
+// when the attempts to fetch a page reach MaxAttempts of the policy, or when ctx is done.
+func paginate[T, C any](ctx context.Context, client *Client, opts *PageOptions, first C, fetch func(ctx context.Context, cursor C) ([]T, C, bool, error)) iter.Seq2[T, error] {
+	var maxItems int
+	if opts != nil {
+		maxItems = opts.MaxItems
+	}
+	policy := opts.pageRetryPolicy(client)
+	return func(yield func(T, error) bool) {
+		var zero T
+		cursor := first
+		count := 0
+		for attempts := 1; ; {
+			items, next, more, err := fetch(ctx, cursor)
+			if err != nil {
+				if !yield(zero, err) {
+					return
+				}
+				if !policy.isRetryablePageError(err) || attempts >= policy.maxAttempts() {
+					return
+				}
+				if err := sleepContext(ctx, policy.backoff(attempts)); err != nil {
+					yield(zero, err)
+					return
+				}
+				attempts++
+				continue
+			}
+			attempts = 1
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+				count++
+				if maxItems > 0 && count >= maxItems {
+					return
+				}
+			}
+			if !more {
+				return
+			}
+			cursor = next
+		}
+	}
+}
+
+// paginateByNextID is like paginate for endpoints that return nextId of the next page.
+func paginateByNextID[T any](ctx context.Context, client *Client, opts *PageOptions, fetch func(ctx context.Context, nextID string) ([]T, string, error)) iter.Seq2[T, error] {
+	return paginate(ctx, client, opts, "", func(ctx context.Context, nextID string) ([]T, string, bool, error) {
+		items, next, err := fetch(ctx, nextID)
+		return items, next, next != "", err
+	})
+}
@@ -0,0 +1,136 @@
+package mackerel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPaginate(t *testing.T) {
+	pages := [][]int{{1, 2}, {3, 4}, {5}}
+	fetch := func(ctx context.Context, page int) ([]int, int, bool, error) {
+		return pages[page], page + 1, page+1 < len(pages), nil
+	}
+
+	t.Run("all", func(t *testing.T) {
+		var got []int
+		for v, err := range paginate(t.Context(), nil, nil, 0, fetch) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, v)
+		}
+		if want := []int{1, 2, 3, 4, 5}; !reflect.DeepEqual(got, want) {
+			t.Errorf("paginate() yields %v; want %v", got, want)
+		}
+	})
+
+	t.Run("MaxItems", func(t *testing.T) {
+		var got []int
+		for v, err := range paginate(t.Context(), nil, &PageOptions{MaxItems: 3}, 0, fetch) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, v)
+		}
+		if want := []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+			t.Errorf("paginate() yields %v; want %v", got, want)
+		}
+	})
+}
+
+func TestPaginate_Retry(t *testing.T) {
+	errTemporary := &APIError{StatusCode: http.StatusServiceUnavailable}
+	failures := 2
+	fetch := func(ctx context.Context, page int) ([]int, int, bool, error) {
+		if page == 1 && failures > 0 {
+			failures--
+			return nil, 0, false, errTemporary
+		}
+		return []int{page}, page + 1, page < 2, nil
+	}
+	opts := &PageOptions{RetryPolicy: &RetryPolicy{BaseBackoff: time.Millisecond}}
+	var (
+		got  []int
+		errs int
+	)
+	for v, err := range paginate(t.Context(), nil, opts, 0, fetch) {
+		if err != nil {
+			if !errors.Is(err, errTemporary) {
+				t.Fatal(err)
+			}
+			errs++
+			continue
+		}
+		got = append(got, v)
+	}
+	if want := []int{0, 1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("paginate() yields %v; want %v", got, want)
+	}
+	if errs != 2 {
+		t.Errorf("paginate() should yield 2 errors but %d", errs)
+	}
+}
+
+func TestPaginate_GiveUp(t *testing.T) {
+	errPermanent := &APIError{StatusCode: http.StatusServiceUnavailable}
+	calls := 0
+	fetch := func(ctx context.Context, page int) ([]int, int, bool, error) {
+		calls++
+		return nil, 0, false, errPermanent
+	}
+	opts := &PageOptions{RetryPolicy: &RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond}}
+	for _, err := range paginate(t.Context(), nil, opts, 0, fetch) {
+		if !errors.Is(err, errPermanent) {
+			t.Errorf("paginate() should yield the error from fetch but %v", err)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("fetch should be called 3 times but %d", calls)
+	}
+}
+
+func TestPaginate_NotRetryable(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *Client
+		opts   *PageOptions
+		err    error
+	}{
+		{
+			name: "not found",
+			opts: &PageOptions{RetryPolicy: &RetryPolicy{BaseBackoff: time.Millisecond}},
+			err:  &APIError{StatusCode: http.StatusNotFound},
+		},
+		{
+			name: "other error",
+			opts: &PageOptions{RetryPolicy: &RetryPolicy{BaseBackoff: time.Millisecond}},
+			err:  errors.New("invalid response"),
+		},
+		{
+			name:   "client retries",
+			client: &Client{RetryPolicy: DefaultRetryPolicy()},
+			err:    &APIError{StatusCode: http.StatusServiceUnavailable},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			fetch := func(ctx context.Context, page int) ([]int, int, bool, error) {
+				calls++
+				return nil, 0, false, tt.err
+			}
+			for _, err := range paginate(t.Context(), tt.client, tt.opts, 0, fetch) {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("paginate() should yield the error from fetch but %v", err)
+				}
+			}
+			if calls != 1 {
+				t.Errorf("fetch should be called once but %d", calls)
+			}
+		})
+	}
+}
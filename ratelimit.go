@@ -0,0 +1,226 @@
+package mackerel
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the rate of requests sent by Client.
+// Client calls Wait before each attempt of a request, including retries,
+// and Observe after each response.
+type RateLimiter interface {
+	// Wait blocks until req is allowed to be sent or the context of req is done.
+	Wait(req *http.Request) error
+	// Observe lets the limiter adapt to the rate-limit headers of resp.
+	Observe(resp *http.Response)
+}
+
+// TokenBucket is a [RateLimiter] that allows requests at Rate per second with bursts of at most Burst.
+//
+// TokenBucket adapts to the rate-limit headers returned from the API.
+// When X-RateLimit-Remaining (or RateLimit-Remaining) and X-RateLimit-Reset (or RateLimit-Reset)
+// are present, the rate is lowered to spread the remaining requests until the reset time.
+// A Retry-After header on 429 Too Many Requests pauses the bucket.
+type TokenBucket struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu          sync.Mutex
+	tokens      float64
+	last        time.Time
+	limitRate   float64
+	limitUntil  time.Time
+	pausedUntil time.Time
+}
+
+var _ RateLimiter = (*TokenBucket)(nil)
+
+// NewTokenBucket returns a new TokenBucket that allows rate requests per second with bursts of burst.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	burst = max(burst, 1)
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		now:    time.Now,
+		tokens: float64(burst),
+	}
+}
+
+// Wait implements [RateLimiter].
+func (b *TokenBucket) Wait(req *http.Request) error {
+	ctx := req.Context()
+	wait, cancel := b.reserve()
+	if err := sleepContext(ctx, wait); err != nil {
+		cancel()
+		return err
+	}
+	return nil
+}
+
+// reserve takes a token and returns how long the caller has to wait to use it.
+func (b *TokenBucket) reserve() (time.Duration, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	rate := b.currentRate(now)
+	b.advance(now, rate)
+	b.tokens--
+	var wait time.Duration
+	if b.tokens < 0 {
+		if rate <= 0 {
+			wait = math.MaxInt64
+		} else {
+			wait = time.Duration(-b.tokens / rate * float64(time.Second))
+		}
+	}
+	if d := b.pausedUntil.Sub(now); d > wait {
+		wait = d
+	}
+	return wait, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.tokens = min(b.tokens+1, b.burst)
+	}
+}
+
+func (b *TokenBucket) advance(now time.Time, rate float64) {
+	if b.last.IsZero() {
+		b.last = now
+		return
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.tokens+elapsed.Seconds()*rate, b.burst)
+		b.last = now
+	}
+}
+
+func (b *TokenBucket) currentRate(now time.Time) float64 {
+	if now.Before(b.limitUntil) {
+		return min(b.rate, b.limitRate)
+	}
+	return b.rate
+}
+
+// Observe implements [RateLimiter].
+func (b *TokenBucket) Observe(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	b.advance(now, b.currentRate(now))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+			b.pausedUntil = maxTime(b.pausedUntil, now.Add(d))
+		}
+	}
+
+	remaining, ok := parseRateLimitHeader(resp.Header, "Remaining")
+	if !ok {
+		return
+	}
+	reset, ok := parseRateLimitReset(resp.Header, now)
+	if !ok {
+		return
+	}
+	if remaining <= 0 {
+		b.pausedUntil = maxTime(b.pausedUntil, reset)
+		return
+	}
+	if d := reset.Sub(now); d > 0 {
+		b.limitRate = remaining / d.Seconds()
+		b.limitUntil = reset
+	}
+}
+
+func parseRateLimitHeader(h http.Header, name string) (float64, bool) {
+	for _, key := range []string{"X-RateLimit-" + name, "RateLimit-" + name} {
+		if s := h.Get(key); s != "" {
+			v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				return 0, false
+			}
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// parseRateLimitReset accepts either seconds until the reset or its Unix time.
+func parseRateLimitReset(h http.Header, now time.Time) (time.Time, bool) {
+	v, ok := parseRateLimitHeader(h, "Reset")
+	if !ok || v < 0 {
+		return time.Time{}, false
+	}
+	if v > 1e9 {
+		return time.Unix(int64(v), 0), true
+	}
+	return now.Add(time.Duration(v * float64(time.Second))), true
+}
+
+func maxTime(t, u time.Time) time.Time {
+	if t.After(u) {
+		return t
+	}
+	return u
+}
+
+// RateLimitRoute routes requests that match Method and PathPrefix to Limiter.
+// Empty Method or PathPrefix matches any request.
+type RateLimitRoute struct {
+	Method     string
+	PathPrefix string
+	Limiter    RateLimiter
+}
+
+func (r *RateLimitRoute) match(req *http.Request) bool {
+	if r.Method != "" && r.Method != req.Method {
+		return false
+	}
+	return strings.HasPrefix(req.URL.Path, r.PathPrefix)
+}
+
+// RouteRateLimiter is a [RateLimiter] that applies separate limiters per HTTP method
+// or endpoint family such as "/api/v0/tsdb".
+// The first matching route in Routes is used. Requests that match no route use Default.
+// When Default is nil, such requests are not limited.
+type RouteRateLimiter struct {
+	Routes  []RateLimitRoute
+	Default RateLimiter
+}
+
+var _ RateLimiter = (*RouteRateLimiter)(nil)
+
+func (l *RouteRateLimiter) limiter(req *http.Request) RateLimiter {
+	for i := range l.Routes {
+		if l.Routes[i].match(req) {
+			return l.Routes[i].Limiter
+		}
+	}
+	return l.Default
+}
+
+// Wait implements [RateLimiter].
+func (l *RouteRateLimiter) Wait(req *http.Request) error {
+	if rl := l.limiter(req); rl != nil {
+		return rl.Wait(req)
+	}
+	return nil
+}
+
+// Observe implements [RateLimiter].
+func (l *RouteRateLimiter) Observe(resp *http.Response) {
+	if resp == nil || resp.Request == nil {
+		return
+	}
+	if rl := l.limiter(resp.Request); rl != nil {
+		rl.Observe(resp)
+	}
+}
@@ -0,0 +1,170 @@
+package mackerel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) add(d time.Duration)     { c.t = c.t.Add(d) }
+func newFakeClock() *fakeClock               { return &fakeClock{t: time.Unix(1700000000, 0)} }
+func (b *TokenBucket) useClock(c *fakeClock) { b.now = c.now }
+
+func TestTokenBucket_reserve(t *testing.T) {
+	clock := newFakeClock()
+	b := NewTokenBucket(2, 2)
+	b.useClock(clock)
+
+	for i := range 2 {
+		if wait, _ := b.reserve(); wait != 0 {
+			t.Errorf("reserve() #%d should not wait within the burst but %v", i, wait)
+		}
+	}
+	if wait, _ := b.reserve(); wait != 500*time.Millisecond {
+		t.Errorf("reserve() should wait 500ms but %v", wait)
+	}
+	clock.add(time.Second)
+	if wait, _ := b.reserve(); wait != 0 {
+		t.Errorf("reserve() should not wait after refilled but %v", wait)
+	}
+}
+
+func TestTokenBucket_Observe(t *testing.T) {
+	t.Run("Retry-After", func(t *testing.T) {
+		clock := newFakeClock()
+		b := NewTokenBucket(100, 10)
+		b.useClock(clock)
+		b.Observe(&http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": []string{"3"}},
+		})
+		if wait, _ := b.reserve(); wait != 3*time.Second {
+			t.Errorf("reserve() should wait for Retry-After but %v", wait)
+		}
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		clock := newFakeClock()
+		b := NewTokenBucket(100, 10)
+		b.useClock(clock)
+		reset := clock.now().Add(20 * time.Second).Unix()
+		b.Observe(&http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"X-Ratelimit-Remaining": []string{"0"},
+				"X-Ratelimit-Reset":     []string{strconv.FormatInt(reset, 10)},
+			},
+		})
+		if wait, _ := b.reserve(); wait != 20*time.Second {
+			t.Errorf("reserve() should wait until the reset but %v", wait)
+		}
+	})
+
+	t.Run("remaining", func(t *testing.T) {
+		clock := newFakeClock()
+		b := NewTokenBucket(100, 1)
+		b.useClock(clock)
+		b.Observe(&http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"Ratelimit-Remaining": []string{"5"},
+				"Ratelimit-Reset":     []string{"10"},
+			},
+		})
+		b.reserve()
+		if wait, _ := b.reserve(); wait != 2*time.Second {
+			t.Errorf("reserve() should be slowed down to 0.5 req/s but %v", wait)
+		}
+		clock.add(12 * time.Second)
+		b.reserve()
+		if wait, _ := b.reserve(); wait != 10*time.Millisecond {
+			t.Errorf("reserve() should be back to 100 req/s after the reset but %v", wait)
+		}
+	})
+}
+
+func TestTokenBucket_WaitCanceled(t *testing.T) {
+	b := NewTokenBucket(0.001, 1)
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+	if err := b.Wait(req); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if err := b.Wait(req.WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() should return context.Canceled but %v", err)
+	}
+	if b.tokens < -0.5 {
+		t.Errorf("the token should be returned on cancel but tokens = %v", b.tokens)
+	}
+}
+
+type countingLimiter struct {
+	waits    int
+	observed int
+}
+
+func (l *countingLimiter) Wait(req *http.Request) error { l.waits++; return nil }
+func (l *countingLimiter) Observe(resp *http.Response)  { l.observed++ }
+
+func TestRouteRateLimiter(t *testing.T) {
+	var tsdb, post, def countingLimiter
+	l := &RouteRateLimiter{
+		Routes: []RateLimitRoute{
+			{PathPrefix: "/api/v0/tsdb", Limiter: &tsdb},
+			{Method: http.MethodPost, Limiter: &post},
+		},
+		Default: &def,
+	}
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/api/v0/tsdb"},
+		{http.MethodGet, "/api/v0/tsdb/latest"},
+		{http.MethodPost, "/api/v0/hosts"},
+		{http.MethodGet, "/api/v0/hosts"},
+	}
+	for _, r := range requests {
+		req, _ := http.NewRequest(r.method, "http://example.com"+r.path, nil)
+		l.Wait(req) // nolint
+		l.Observe(&http.Response{Request: req})
+	}
+	if tsdb.waits != 2 || tsdb.observed != 2 {
+		t.Errorf("tsdb limiter should be used twice but %+v", tsdb)
+	}
+	if post.waits != 1 || post.observed != 1 {
+		t.Errorf("post limiter should be used once but %+v", post)
+	}
+	if def.waits != 1 || def.observed != 1 {
+		t.Errorf("default limiter should be used once but %+v", def)
+	}
+}
+
+func TestRequest_RateLimiter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(`{}`)) // nolint
+	}))
+	defer ts.Close()
+
+	client, _ := NewClientWithOptions("dummy-key", ts.URL, false)
+	var l countingLimiter
+	client.RateLimiter = &l
+	for range 3 {
+		if _, err := requestGetContext[any](t.Context(), client, "/"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if l.waits != 3 || l.observed != 3 {
+		t.Errorf("limiter should be used for each request but %+v", l)
+	}
+}
@@ -0,0 +1,224 @@
+package mackerel
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts = 4
+	defaultRetryBaseBackoff = 500 * time.Millisecond
+	defaultRetryMaxBackoff  = 30 * time.Second
+	defaultRetryJitter      = 0.2
+)
+
+var defaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy configures how Client retries failed requests.
+// Zero-valued MaxAttempts, BaseBackoff, MaxBackoff and RetryableStatusCodes
+// fall back to the defaults of [DefaultRetryPolicy].
+//
+// Requests whose body cannot be replayed, that is, requests without GetBody,
+// are never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	MaxAttempts int
+	// BaseBackoff is the wait before the first retry. It doubles on each retry.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the exponential backoff.
+	// A longer Retry-After header from the API is still honored.
+	MaxBackoff time.Duration
+	// Jitter is the fraction, between 0 and 1, of each backoff that is randomized.
+	Jitter float64
+	// RetryableStatusCodes lists the HTTP status codes to retry.
+	RetryableStatusCodes []int
+	// IsRetryableError reports whether an error from the HTTP client should be retried.
+	// When nil, connection-level errors are retried.
+	IsRetryableError func(err error) bool
+	// RetryNonIdempotent allows retrying requests such as POST on
+	// retryable status codes and on errors that may happen after the server received them.
+	// Without it they are retried only when the connection could not be established.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the default retry policy.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:          defaultRetryMaxAttempts,
+		BaseBackoff:          defaultRetryBaseBackoff,
+		MaxBackoff:           defaultRetryMaxBackoff,
+		Jitter:               defaultRetryJitter,
+		RetryableStatusCodes: slices.Clone(defaultRetryableStatusCodes),
+	}
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil {
+		return 1
+	}
+	if p.MaxAttempts <= 0 {
+		return defaultRetryMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+// backoff returns the wait before the n-th retry, starting from 1.
+func (p *RetryPolicy) backoff(n int) time.Duration {
+	base := cmp.Or(p.BaseBackoff, defaultRetryBaseBackoff)
+	limit := cmp.Or(p.MaxBackoff, defaultRetryMaxBackoff)
+	d := base
+	for i := 1; i < n && d < limit; i++ {
+		d *= 2
+	}
+	d = min(d, limit)
+	jitter := min(max(p.Jitter, 0), 1)
+	return d - time.Duration(rand.Float64()*jitter*float64(d))
+}
+
+func (p *RetryPolicy) isRetryableStatus(code int) bool {
+	codes := p.RetryableStatusCodes
+	if codes == nil {
+		codes = defaultRetryableStatusCodes
+	}
+	return slices.Contains(codes, code)
+}
+
+func (p *RetryPolicy) isRetryableError(err error) bool {
+	if p.IsRetryableError != nil {
+		return p.IsRetryableError(err)
+	}
+	return isConnectionError(err)
+}
+
+// retryDelay reports whether the request should be attempted again after
+// the n-th attempt has finished with resp or err, and how long to wait before that.
+func (p *RetryPolicy) retryDelay(req *http.Request, n int, resp *http.Response, err error) (time.Duration, bool) {
+	if n >= p.maxAttempts() {
+		return 0, false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return 0, false
+	}
+	if req.Context().Err() != nil {
+		return 0, false
+	}
+	var wait time.Duration
+	switch {
+	case err != nil:
+		if !p.isRetryableError(err) {
+			return 0, false
+		}
+		// The server may have processed the request before the connection was lost.
+		if !isIdempotent(req.Method) && !p.RetryNonIdempotent && !isDialError(err) {
+			return 0, false
+		}
+		wait = p.backoff(n)
+	case p.isRetryableStatus(resp.StatusCode):
+		if !isIdempotent(req.Method) && !p.RetryNonIdempotent {
+			return 0, false
+		}
+		var ok bool
+		if wait, ok = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); !ok {
+			wait = p.backoff(n)
+		}
+	default:
+		return 0, false
+	}
+	if deadline, ok := req.Context().Deadline(); ok && time.Now().Add(wait).After(deadline) {
+		return 0, false
+	}
+	return wait, true
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isConnectionError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isDialError reports whether err means that the request was never sent to the server.
+func isDialError(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// parseRetryAfter parses the value of Retry-After header, which is either
+// delay-seconds or an HTTP-date.
+func parseRetryAfter(s string, now time.Time) (time.Duration, bool) {
+	if s == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 {
+			return 0, false
+		}
+		return time.Duration(n) * time.Second, true
+	}
+	t, err := http.ParseTime(s)
+	if err != nil {
+		return 0, false
+	}
+	return max(t.Sub(now), 0), true
+}
+
+// rewindRequest returns a shallow copy of req whose body is reset for another attempt.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,225 @@
+package mackerel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newRetryTestClient(t *testing.T, url string, policy *RetryPolicy) *Client {
+	t.Helper()
+	client, err := NewClientWithOptions("dummy-key", url, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RetryPolicy = policy
+	return client
+}
+
+func TestRequest_RetryOnStatus(t *testing.T) {
+	var count atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if count.Add(1) < 3 {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		res.Write([]byte(`{"success": true}`)) // nolint
+	}))
+	defer ts.Close()
+
+	client := newRetryTestClient(t, ts.URL, &RetryPolicy{BaseBackoff: time.Millisecond})
+	res, err := requestGetContext[struct {
+		Success bool `json:"success"`
+	}](t.Context(), client, "/")
+	if err != nil {
+		t.Fatalf("request is error %v", err)
+	}
+	if !res.Success {
+		t.Errorf("response is invalid %v", res)
+	}
+	if n := count.Load(); n != 3 {
+		t.Errorf("the server should be requested 3 times but %d", n)
+	}
+}
+
+func TestRequest_RetryGiveUp(t *testing.T) {
+	var count atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		count.Add(1)
+		res.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	client := newRetryTestClient(t, ts.URL, &RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond})
+	_, err := requestGetContext[any](t.Context(), client, "/")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("err should be an APIError with 502 but %v", err)
+	}
+	if n := count.Load(); n != 2 {
+		t.Errorf("the server should be requested 2 times but %d", n)
+	}
+}
+
+func TestRequest_RetryNonIdempotent(t *testing.T) {
+	tests := []struct {
+		name               string
+		retryNonIdempotent bool
+		wantCount          int32
+	}{
+		{"default", false, 1},
+		{"opted in", true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var count atomic.Int32
+			ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				body, _ := io.ReadAll(req.Body)
+				if string(body) != "{\"name\":\"test\"}\n" {
+					t.Errorf("request body should be replayed but %q", body)
+				}
+				if count.Add(1) == 1 {
+					res.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				res.Write([]byte(`{}`)) // nolint
+			}))
+			defer ts.Close()
+
+			client := newRetryTestClient(t, ts.URL, &RetryPolicy{
+				BaseBackoff:        time.Millisecond,
+				RetryNonIdempotent: tt.retryNonIdempotent,
+			})
+			requestPostContext[any](t.Context(), client, "/", map[string]string{"name": "test"}) // nolint
+			if n := count.Load(); n != tt.wantCount {
+				t.Errorf("the server should be requested %d times but %d", tt.wantCount, n)
+			}
+		})
+	}
+}
+
+func TestRequest_RetryRespectsDeadline(t *testing.T) {
+	var count atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		count.Add(1)
+		res.Header().Set("Retry-After", "3600")
+		res.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	client := newRetryTestClient(t, ts.URL, DefaultRetryPolicy())
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+	_, err := requestGetContext[any](ctx, client, "/")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("err should be an APIError with 429 but %v", err)
+	}
+	if n := count.Load(); n != 1 {
+		t.Errorf("the server should be requested once but %d", n)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"Mon, 01 Jan 2024 00:00:30 GMT", 30 * time.Second, true},
+		{"Sun, 31 Dec 2023 23:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %t; want %v, %t", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := &RetryPolicy{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if d := p.backoff(i + 1); d != w {
+			t.Errorf("backoff(%d) = %v; want %v", i+1, d, w)
+		}
+	}
+
+	p.Jitter = 0.5
+	for n := 1; n <= 5; n++ {
+		d := p.backoff(n)
+		if w := want[n-1]; d > w || d < w/2 {
+			t.Errorf("backoff(%d) = %v; want between %v and %v", n, d, w/2, w)
+		}
+	}
+}
+
+func TestRequest_RetryOnConnectionError(t *testing.T) {
+	var count atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if count.Add(1) == 1 {
+			conn, _, err := res.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close() // nolint
+			return
+		}
+		res.Write([]byte(`{}`)) // nolint
+	}))
+	defer ts.Close()
+
+	client := newRetryTestClient(t, ts.URL, &RetryPolicy{BaseBackoff: time.Millisecond})
+	if _, err := requestGetContext[any](t.Context(), client, "/"); err != nil {
+		t.Errorf("request is error %v", err)
+	}
+	if n := count.Load(); n != 2 {
+		t.Errorf("the server should be requested 2 times but %d", n)
+	}
+
+	// POST is not sent again because the server may have processed it.
+	count.Store(0)
+	if _, err := requestPostContext[any](t.Context(), client, "/", map[string]string{}); err == nil {
+		t.Error("request should fail")
+	}
+	if n := count.Load(); n != 1 {
+		t.Errorf("the server should be requested once but %d", n)
+	}
+}
+
+func TestRequest_RetryNonIdempotentOnDialError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {}))
+	url := ts.URL
+	ts.Close()
+
+	transport := &countingTransport{}
+	client := newRetryTestClient(t, url, &RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond})
+	client.HTTPClient = &http.Client{Transport: transport}
+	if _, err := requestPostContext[any](t.Context(), client, "/", map[string]string{}); err == nil {
+		t.Error("request should fail")
+	}
+	if n := transport.count.Load(); n != 3 {
+		t.Errorf("refused POST should be attempted 3 times but %d", n)
+	}
+}
+
+type countingTransport struct {
+	count atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.count.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
@@ -25,6 +25,7 @@ func (c *Client) GetRoleMetaData(serviceName, roleName, namespace string) (*Role
 
 // GetRoleMetaDataContext gets a role metadata.
 func (c *Client) GetRoleMetaDataContext(ctx context.Context, serviceName, roleName, namespace string) (*RoleMetaDataResp, error) {
+	ctx = withOperationName(ctx, "GetRoleMetaData")
 	path := fmt.Sprintf("/api/v0/services/%s/roles/%s/metadata/%s", serviceName, roleName, namespace)
 	metadata, header, err := requestGetAndReturnHeaderContext[HostMetaData](ctx, c, path)
 	if err != nil {
@@ -44,6 +45,7 @@ func (c *Client) GetRoleMetaDataNameSpaces(serviceName, roleName string) ([]stri
 
 // GetRoleMetaDataNameSpacesContext fetches namespaces of role metadata.
 func (c *Client) GetRoleMetaDataNameSpacesContext(ctx context.Context, serviceName, roleName string) ([]string, error) {
+	ctx = withOperationName(ctx, "GetRoleMetaDataNameSpaces")
 	data, err := requestGetContext[struct {
 		MetaDatas []struct {
 			NameSpace string `json:"namespace"`
@@ -66,6 +68,7 @@ func (c *Client) PutRoleMetaData(serviceName, roleName, namespace string, metada
 
 // PutRoleMetaDataContext puts a role metadata.
 func (c *Client) PutRoleMetaDataContext(ctx context.Context, serviceName, roleName, namespace string, metadata RoleMetaData) error {
+	ctx = withOperationName(ctx, "PutRoleMetaData")
 	path := fmt.Sprintf("/api/v0/services/%s/roles/%s/metadata/%s", serviceName, roleName, namespace)
 	_, err := requestPutContext[any](ctx, c, path, metadata)
 	return err
@@ -73,13 +76,12 @@ func (c *Client) PutRoleMetaDataContext(ctx context.Context, serviceName, roleNa
 
 // DeleteRoleMetaData deletes a role metadata.
 func (c *Client) DeleteRoleMetaData(serviceName, roleName, namespace string) error {
-	path := fmt.Sprintf("/api/v0/services/%s/roles/%s/metadata/%s", serviceName, roleName, namespace)
-	_, err := requestDeleteContext[any](context.Background(), c, path)
-	return err
+	return c.DeleteRoleMetaDataContext(context.Background(), serviceName, roleName, namespace)
 }
 
 // DeleteRoleMetaDataContext is like [DeleteRoleMetaData].
 func (c *Client) DeleteRoleMetaDataContext(ctx context.Context, serviceName, roleName, namespace string) error {
+	ctx = withOperationName(ctx, "DeleteRoleMetaData")
 	path := fmt.Sprintf("/api/v0/services/%s/roles/%s/metadata/%s", serviceName, roleName, namespace)
 	_, err := requestDeleteContext[any](ctx, c, path)
 	return err
@@ -21,6 +21,7 @@ func (c *Client) FindRoles(serviceName string) ([]*Role, error) {
 
 // FindRolesContext finds roles.
 func (c *Client) FindRolesContext(ctx context.Context, serviceName string) ([]*Role, error) {
+	ctx = withOperationName(ctx, "FindRoles")
 	data, err := requestGetContext[struct {
 		Roles []*Role `json:"roles"`
 	}](ctx, c, fmt.Sprintf("/api/v0/services/%s/roles", serviceName))
@@ -37,18 +38,19 @@ func (c *Client) CreateRole(serviceName string, param *CreateRoleParam) (*Role,
 
 // CreateRoleContext creates a role.
 func (c *Client) CreateRoleContext(ctx context.Context, serviceName string, param *CreateRoleParam) (*Role, error) {
+	ctx = withOperationName(ctx, "CreateRole")
 	path := fmt.Sprintf("/api/v0/services/%s/roles", serviceName)
 	return requestPostContext[Role](ctx, c, path, param)
 }
 
 // DeleteRole deletes a role.
 func (c *Client) DeleteRole(serviceName, roleName string) (*Role, error) {
-	path := fmt.Sprintf("/api/v0/services/%s/roles/%s", serviceName, roleName)
-	return requestDeleteContext[Role](context.Background(), c, path)
+	return c.DeleteRoleContext(context.Background(), serviceName, roleName)
 }
 
 // DeleteRoleContext is like [DeleteRole].
 func (c *Client) DeleteRoleContext(ctx context.Context, serviceName, roleName string) (*Role, error) {
+	ctx = withOperationName(ctx, "DeleteRole")
 	path := fmt.Sprintf("/api/v0/services/%s/roles/%s", serviceName, roleName)
 	return requestDeleteContext[Role](ctx, c, path)
 }
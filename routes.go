@@ -0,0 +1,89 @@
+package mackerel
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// routes are the path templates of the API endpoints used by Client.
+var routes = []string{
+	"/api/v0/alert-group-settings",
+	"/api/v0/alert-group-settings/{id}",
+	"/api/v0/alerts",
+	"/api/v0/alerts/{alertId}",
+	"/api/v0/alerts/{alertId}/close",
+	"/api/v0/alerts/{alertId}/logs",
+	"/api/v0/apm/db-query-stats",
+	"/api/v0/apm/http-server-stats",
+	"/api/v0/aws-integrations",
+	"/api/v0/aws-integrations/{awsIntegrationId}",
+	"/api/v0/aws-integrations-excludable-metrics",
+	"/api/v0/aws-integrations-external-id",
+	"/api/v0/channels",
+	"/api/v0/channels/{channelId}",
+	"/api/v0/dashboards",
+	"/api/v0/dashboards/{dashboardId}",
+	"/api/v0/downtimes",
+	"/api/v0/downtimes/{downtimeId}",
+	"/api/v0/graph-annotations",
+	"/api/v0/graph-annotations/{annotationId}",
+	"/api/v0/graph-defs",
+	"/api/v0/graph-defs/create",
+	"/api/v0/hosts",
+	"/api/v0/hosts/bulk-retire",
+	"/api/v0/hosts/bulk-update-statuses",
+	"/api/v0/hosts/{hostId}",
+	"/api/v0/hosts/{hostId}/metadata",
+	"/api/v0/hosts/{hostId}/metadata/{namespace}",
+	"/api/v0/hosts/{hostId}/metric-names",
+	"/api/v0/hosts/{hostId}/metrics",
+	"/api/v0/hosts/{hostId}/monitored-statuses",
+	"/api/v0/hosts/{hostId}/retire",
+	"/api/v0/hosts/{hostId}/role-fullnames",
+	"/api/v0/hosts/{hostId}/status",
+	"/api/v0/hosts-by-custom-identifier/{customIdentifier}",
+	"/api/v0/invitations",
+	"/api/v0/monitoring/checks",
+	"/api/v0/monitoring/checks/report",
+	"/api/v0/monitors",
+	"/api/v0/monitors/{monitorId}",
+	"/api/v0/notification-groups",
+	"/api/v0/notification-groups/{notificationGroupId}",
+	"/api/v0/org",
+	"/api/v0/services",
+	"/api/v0/services/{serviceName}",
+	"/api/v0/services/{serviceName}/graph-defs/{graphName}",
+	"/api/v0/services/{serviceName}/metadata",
+	"/api/v0/services/{serviceName}/metadata/{namespace}",
+	"/api/v0/services/{serviceName}/metric-names",
+	"/api/v0/services/{serviceName}/metrics",
+	"/api/v0/services/{serviceName}/roles",
+	"/api/v0/services/{serviceName}/roles/{roleName}",
+	"/api/v0/services/{serviceName}/roles/{roleName}/metadata",
+	"/api/v0/services/{serviceName}/roles/{roleName}/metadata/{namespace}",
+	"/api/v0/services/{serviceName}/tsdb",
+	"/api/v0/traces",
+	"/api/v0/traces/{traceId}",
+	"/api/v0/tsdb",
+	"/api/v0/tsdb/latest",
+	"/api/v0/users",
+	"/api/v0/users/{userId}",
+}
+
+// routeMux matches paths to routes, built on the first use.
+var routeMux = sync.OnceValue(func() *http.ServeMux {
+	mux := http.NewServeMux()
+	for _, route := range routes {
+		mux.Handle(route, http.NotFoundHandler())
+	}
+	return mux
+})
+
+// Route returns the path template of the API endpoint of op, such as "/api/v0/hosts/{hostId}".
+// It is suitable to group operations with low cardinality, unlike Path.
+// It returns "" if the endpoint is unknown to Client.
+func (op *Operation) Route() string {
+	_, pattern := routeMux().Handler(&http.Request{Method: op.Method, URL: &url.URL{Path: op.Path}})
+	return pattern
+}
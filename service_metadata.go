@@ -25,6 +25,7 @@ func (c *Client) GetServiceMetaData(serviceName, namespace string) (*ServiceMeta
 
 // GetServiceMetaDataContext gets service metadata.
 func (c *Client) GetServiceMetaDataContext(ctx context.Context, serviceName, namespace string) (*ServiceMetaDataResp, error) {
+	ctx = withOperationName(ctx, "GetServiceMetaData")
 	path := fmt.Sprintf("/api/v0/services/%s/metadata/%s", serviceName, namespace)
 	metadata, header, err := requestGetAndReturnHeaderContext[HostMetaData](ctx, c, path)
 	if err != nil {
@@ -44,6 +45,7 @@ func (c *Client) GetServiceMetaDataNameSpaces(serviceName string) ([]string, err
 
 // GetServiceMetaDataNameSpacesContext fetches namespaces of service metadata.
 func (c *Client) GetServiceMetaDataNameSpacesContext(ctx context.Context, serviceName string) ([]string, error) {
+	ctx = withOperationName(ctx, "GetServiceMetaDataNameSpaces")
 	data, err := requestGetContext[struct {
 		MetaDatas []struct {
 			NameSpace string `json:"namespace"`
@@ -66,6 +68,7 @@ func (c *Client) PutServiceMetaData(serviceName, namespace string, metadata Serv
 
 // v puts a service metadata.
 func (c *Client) PutServiceMetaDataContext(ctx context.Context, serviceName, namespace string, metadata ServiceMetaData) error {
+	ctx = withOperationName(ctx, "PutServiceMetaData")
 	path := fmt.Sprintf("/api/v0/services/%s/metadata/%s", serviceName, namespace)
 	_, err := requestPutContext[any](ctx, c, path, metadata)
 	return err
@@ -78,6 +81,7 @@ func (c *Client) DeleteServiceMetaData(serviceName, namespace string) error {
 
 // DeleteServiceMetaDataContext is like [DeleteServiceMetaData].
 func (c *Client) DeleteServiceMetaDataContext(ctx context.Context, serviceName, namespace string) error {
+	ctx = withOperationName(ctx, "DeleteServiceMetaData")
 	path := fmt.Sprintf("/api/v0/services/%s/metadata/%s", serviceName, namespace)
 	_, err := requestDeleteContext[any](ctx, c, path)
 	return err
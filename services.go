@@ -25,6 +25,7 @@ func (c *Client) FindServices() ([]*Service, error) {
 
 // FindServicesContext finds services.
 func (c *Client) FindServicesContext(ctx context.Context) ([]*Service, error) {
+	ctx = withOperationName(ctx, "FindServices")
 	data, err := requestGetContext[struct {
 		Services []*Service `json:"services"`
 	}](ctx, c, "/api/v0/services")
@@ -41,17 +42,18 @@ func (c *Client) CreateService(param *CreateServiceParam) (*Service, error) {
 
 // CreateServiceContext creates a service.
 func (c *Client) CreateServiceContext(ctx context.Context, param *CreateServiceParam) (*Service, error) {
+	ctx = withOperationName(ctx, "CreateService")
 	return requestPostContext[Service](ctx, c, "/api/v0/services", param)
 }
 
 // DeleteService deletes a service.
 func (c *Client) DeleteService(serviceName string) (*Service, error) {
-	path := fmt.Sprintf("/api/v0/services/%s", serviceName)
-	return requestDeleteContext[Service](context.Background(), c, path)
+	return c.DeleteServiceContext(context.Background(), serviceName)
 }
 
 // DeleteServiceContext is like [DeleteService].
 func (c *Client) DeleteServiceContext(ctx context.Context, serviceName string) (*Service, error) {
+	ctx = withOperationName(ctx, "DeleteService")
 	path := fmt.Sprintf("/api/v0/services/%s", serviceName)
 	return requestDeleteContext[Service](ctx, c, path)
 }
@@ -63,6 +65,7 @@ func (c *Client) ListServiceMetricNames(serviceName string) ([]string, error) {
 
 // ListServiceMetricNamesContext lists metric names of a service.
 func (c *Client) ListServiceMetricNamesContext(ctx context.Context, serviceName string) ([]string, error) {
+	ctx = withOperationName(ctx, "ListServiceMetricNames")
 	data, err := requestGetContext[struct {
 		Names []string `json:"names"`
 	}](ctx, c, fmt.Sprintf("/api/v0/services/%s/metric-names", serviceName))
@@ -74,13 +77,12 @@ func (c *Client) ListServiceMetricNamesContext(ctx context.Context, serviceName
 
 // DeleteServiceGraphDef deletes a service metrics graph definition.
 func (c *Client) DeleteServiceGraphDef(serviceName string, graphName string) error {
-	path := fmt.Sprintf("/api/v0/services/%s/graph-defs/%s", serviceName, graphName)
-	_, err := requestDeleteContext[any](context.Background(), c, path)
-	return err
+	return c.DeleteServiceGraphDefContext(context.Background(), serviceName, graphName)
 }
 
 // DeleteServiceGraphDefContext is like [DeleteServiceGraphDef].
 func (c *Client) DeleteServiceGraphDefContext(ctx context.Context, serviceName string, graphName string) error {
+	ctx = withOperationName(ctx, "DeleteServiceGraphDef")
 	path := fmt.Sprintf("/api/v0/services/%s/graph-defs/%s", serviceName, graphName)
 	_, err := requestDeleteContext[any](ctx, c, path)
 	return err
@@ -100,11 +100,12 @@ type ListTracesResult struct {
 
 // ListTraces searches traces
 func (c *Client) ListTraces(params *ListTracesParam) (*ListTracesResponse, error) {
-	return requestPostContext[ListTracesResponse](context.Background(), c, "/api/v0/traces", params)
+	return c.ListTracesContext(context.Background(), params)
 }
 
 // ListTracesContext is like [ListTraces].
 func (c *Client) ListTracesContext(ctx context.Context, params *ListTracesParam) (*ListTracesResponse, error) {
+	ctx = withOperationName(ctx, "ListTraces")
 	return requestPostContext[ListTracesResponse](ctx, c, "/api/v0/traces", params)
 }
 
@@ -114,43 +115,28 @@ func (c *Client) ListTracesContext(ctx context.Context, params *ListTracesParam)
 // then the iterator continues its results.
 // Errors returned from the API are reported to the consumer via the second parameter of the iterator.
 //
-// If an error occurs, the iterator will retry the request with the exact same params
-// on subsequent calls until a successful response is received.
-// Note that the iterator does not include a delay between retries.
-// Therefore, the consumer should wait for sufficient period before each retry.
+// If a retryable error occurs and the consumer continues, the iterator will retry the request
+// with the exact same params after a backoff of [DefaultRetryPolicy], unless the client has its own RetryPolicy.
+// It stops after the attempts reach MaxAttempts of the policy, or on the other errors.
 func (c *Client) ListTracesSeq(ctx context.Context, params *ListTracesParam) iter.Seq2[*ListTracesResult, error] {
-	return func(yield func(*ListTracesResult, error) bool) {
-		page := 1
-		if params.Page != nil {
-			page = *params.Page
-		}
-		n := 20
-		if params.PerPage != nil {
-			n = *params.PerPage
-		}
-		params := *params
+	var p ListTracesParam
+	if params != nil {
+		p = *params
+	}
+	n := 20
+	if p.PerPage != nil {
+		n = *p.PerPage
+	}
+	p.PerPage = &n
+	return paginate(ctx, c, nil, firstPage(p.Page), func(ctx context.Context, page int) ([]*ListTracesResult, int, bool, error) {
+		params := p
 		params.Page = &page
-		params.PerPage = &n
-		for {
-			res, err := c.ListTracesContext(ctx, &params)
-			if err != nil {
-				if !yield(nil, err) {
-					return
-				}
-				continue
-			}
-			for _, r := range res.Results {
-				if !yield(r, nil) {
-					return
-				}
-			}
-			if !res.HasNextPage {
-				break
-			}
-			nextPage := *params.Page + 1
-			params.Page = &nextPage
+		res, err := c.ListTracesContext(ctx, &params)
+		if err != nil {
+			return nil, page, false, err
 		}
-	}
+		return res.Results, page + 1, res.HasNextPage, nil
+	})
 }
 
 // TraceResponse represents the response structure from the traces API
@@ -271,10 +257,11 @@ const (
 
 // GetTrace gets detailed trace information for the specified trace ID
 func (c *Client) GetTrace(traceID string) (*TraceResponse, error) {
-	return requestGetContext[TraceResponse](context.Background(), c, fmt.Sprintf("/api/v0/traces/%s", traceID))
+	return c.GetTraceContext(context.Background(), traceID)
 }
 
 // GetTraceContext is like [GetTrace].
 func (c *Client) GetTraceContext(ctx context.Context, traceID string) (*TraceResponse, error) {
+	ctx = withOperationName(ctx, "GetTrace")
 	return requestGetContext[TraceResponse](ctx, c, fmt.Sprintf("/api/v0/traces/%s", traceID))
 }
@@ -25,6 +25,7 @@ func (c *Client) FindUsers() ([]*User, error) {
 
 // FindUsersContext finds users.
 func (c *Client) FindUsersContext(ctx context.Context) ([]*User, error) {
+	ctx = withOperationName(ctx, "FindUsers")
 	data, err := requestGetContext[struct {
 		Users []*User `json:"users"`
 	}](ctx, c, "/api/v0/users")
@@ -36,12 +37,12 @@ func (c *Client) FindUsersContext(ctx context.Context) ([]*User, error) {
 
 // DeleteUser deletes a user.
 func (c *Client) DeleteUser(userID string) (*User, error) {
-	path := fmt.Sprintf("/api/v0/users/%s", userID)
-	return requestDeleteContext[User](context.Background(), c, path)
+	return c.DeleteUserContext(context.Background(), userID)
 }
 
 // DeleteUserContext is like [DeleteUser].
 func (c *Client) DeleteUserContext(ctx context.Context, userID string) (*User, error) {
+	ctx = withOperationName(ctx, "DeleteUser")
 	path := fmt.Sprintf("/api/v0/users/%s", userID)
 	return requestDeleteContext[User](ctx, c, path)
 }